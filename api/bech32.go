@@ -0,0 +1,212 @@
+// +build !secretcli
+
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBech32Prefix is the account address prefix of the Secret Network mainnet
+const DefaultBech32Prefix = "secret"
+
+// DefaultAddressLengths are the accepted lengths of canonical addresses: 20 bytes for
+// regular accounts, 32 bytes for e.g. module or contract addresses on some chains
+var DefaultAddressLengths = []int{20, 32}
+
+// Bech32API converts between canonical (raw bytes) and bech32 encoded human addresses
+// for a configurable prefix, so chains with a non-standard prefix (e.g. testnets) get
+// a working GoAPI without writing their own.
+type Bech32API struct {
+	// Bech32Prefix is the human readable part of all addresses, e.g. "secret"
+	Bech32Prefix string
+	// Cost is the gas charged for every conversion
+	Cost uint64
+	// AddressLengths are the accepted lengths (in bytes) of canonical addresses
+	AddressLengths []int
+}
+
+// NewBech32API creates a Bech32API for the given prefix, accepting addresses of DefaultAddressLengths.
+// An empty prefix means DefaultBech32Prefix.
+func NewBech32API(prefix string, cost uint64) Bech32API {
+	if prefix == "" {
+		prefix = DefaultBech32Prefix
+	}
+	return Bech32API{Bech32Prefix: prefix, Cost: cost, AddressLengths: DefaultAddressLengths}
+}
+
+// checkLength returns an error unless canon has one of the accepted lengths.
+// If no lengths are configured, any non-empty address is accepted.
+func (b Bech32API) checkLength(canon []byte) error {
+	if len(canon) == 0 {
+		return fmt.Errorf("empty canonical address")
+	}
+	if len(b.AddressLengths) == 0 {
+		return nil
+	}
+	for _, l := range b.AddressLengths {
+		if len(canon) == l {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid canonical address length %d, expected one of %v", len(canon), b.AddressLengths)
+}
+
+// GoAPI returns the callbacks to pass to the vm
+func (b Bech32API) GoAPI() GoAPI {
+	return GoAPI{
+		HumanAddress:     b.HumanizeAddress,
+		CanonicalAddress: b.CanonicalizeAddress,
+	}
+}
+
+// HumanizeAddress encodes a canonical address with the configured prefix
+func (b Bech32API) HumanizeAddress(canon []byte) (string, uint64, error) {
+	if err := b.checkLength(canon); err != nil {
+		return "", b.Cost, err
+	}
+	data, err := convertBits(canon, 8, 5, true)
+	if err != nil {
+		return "", b.Cost, err
+	}
+	return bech32Encode(b.Bech32Prefix, data), b.Cost, nil
+}
+
+// CanonicalizeAddress decodes a bech32 address, rejecting any prefix but the configured one
+func (b Bech32API) CanonicalizeAddress(human string) ([]byte, uint64, error) {
+	canon, err := b.decode(human)
+	return canon, b.Cost, err
+}
+
+// ValidateAddress returns an error if human is not a valid bech32 address with the configured prefix
+func (b Bech32API) ValidateAddress(human string) error {
+	_, err := b.decode(human)
+	return err
+}
+
+func (b Bech32API) decode(human string) ([]byte, error) {
+	prefix, data, err := bech32Decode(human)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != b.Bech32Prefix {
+		return nil, fmt.Errorf("invalid bech32 prefix: expected %q, got %q", b.Bech32Prefix, prefix)
+	}
+	canon, err := convertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.checkLength(canon); err != nil {
+		return nil, err
+	}
+	return canon, nil
+}
+
+/**** bech32 encoding, see BIP-173 ****/
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HrpExpand(hrp string) []byte {
+	res := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		res = append(res, hrp[i]>>5)
+	}
+	res = append(res, 0)
+	for i := 0; i < len(hrp); i++ {
+		res = append(res, hrp[i]&31)
+	}
+	return res
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32HrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ 1
+	res := make([]byte, 6)
+	for i := range res {
+		res[i] = byte((mod >> uint(5*(5-i))) & 31)
+	}
+	return res
+}
+
+func bech32Encode(hrp string, data []byte) string {
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range append(data, bech32Checksum(hrp, data)...) {
+		sb.WriteByte(bech32Charset[v])
+	}
+	return sb.String()
+}
+
+func bech32Decode(addr string) (string, []byte, error) {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return "", nil, fmt.Errorf("invalid bech32 address %q: mixed case", addr)
+	}
+	addr = strings.ToLower(addr)
+	sep := strings.LastIndexByte(addr, '1')
+	if sep < 1 || sep+7 > len(addr) {
+		return "", nil, fmt.Errorf("invalid bech32 address %q: bad separator position", addr)
+	}
+	hrp := addr[:sep]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("invalid bech32 address %q: bad prefix character", addr)
+		}
+	}
+	data := make([]byte, 0, len(addr)-sep-1)
+	for _, c := range addr[sep+1:] {
+		idx := strings.IndexRune(bech32Charset, c)
+		if idx < 0 {
+			return "", nil, fmt.Errorf("invalid bech32 address %q: bad character %q", addr, c)
+		}
+		data = append(data, byte(idx))
+	}
+	if bech32Polymod(append(bech32HrpExpand(hrp), data...)) != 1 {
+		return "", nil, fmt.Errorf("invalid bech32 address %q: bad checksum", addr)
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// convertBits regroups data from fromBits to toBits sized groups
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	res := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, fmt.Errorf("invalid data: value out of range")
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			res = append(res, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			res = append(res, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, fmt.Errorf("invalid data: bad padding")
+	}
+	return res, nil
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBech32KnownAddress(t *testing.T) {
+	// BIP-173 test vector, containing every character once
+	addr := "abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw"
+	expected := make([]byte, 32)
+	for i := range expected {
+		expected[i] = byte(i)
+	}
+	hrp, data, err := bech32Decode(addr)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", hrp)
+	assert.Equal(t, expected, data)
+	assert.Equal(t, addr, bech32Encode(hrp, data))
+
+	// upper case is fine as well
+	_, _, err = bech32Decode("A12UEL5L")
+	require.NoError(t, err)
+}
+
+func TestBech32CustomPrefix(t *testing.T) {
+	canon := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14}
+	testnet := NewBech32API("secrettest", 17)
+	mainnet := NewBech32API("", 17)
+	assert.Equal(t, DefaultBech32Prefix, mainnet.Bech32Prefix)
+
+	// humanizing uses the configured prefix
+	human, cost, err := testnet.HumanizeAddress(canon)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(17), cost)
+	assert.Regexp(t, "^secrettest1[a-z0-9]{38}$", human)
+	other, _, err := mainnet.HumanizeAddress(canon)
+	require.NoError(t, err)
+	assert.Regexp(t, "^secret1[a-z0-9]{38}$", other)
+
+	// and we get back what we put in
+	recover, cost, err := testnet.CanonicalizeAddress(human)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(17), cost)
+	assert.Equal(t, canon, recover)
+	require.NoError(t, testnet.ValidateAddress(human))
+
+	// a mismatched prefix is rejected
+	_, _, err = testnet.CanonicalizeAddress(other)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `expected "secrettest", got "secret"`)
+	require.Error(t, mainnet.ValidateAddress(human))
+
+	// as is a broken checksum
+	broken := human[:len(human)-1] + "q"
+	if broken == human {
+		broken = human[:len(human)-1] + "p"
+	}
+	err = testnet.ValidateAddress(broken)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad checksum")
+
+	// the GoAPI callbacks work the same
+	api := testnet.GoAPI()
+	h, _, err := api.HumanAddress(canon)
+	require.NoError(t, err)
+	assert.Equal(t, human, h)
+	c, _, err := api.CanonicalAddress(human)
+	require.NoError(t, err)
+	assert.Equal(t, canon, c)
+}
+
+func TestBech32RejectsInvalid(t *testing.T) {
+	b := NewBech32API("secret", 0)
+	for _, addr := range []string{"", "secret", "secret1", "Secret1qqqqqqqqq", "secret1bbbbbbbbbbbb", "1qqqqqqqq"} {
+		assert.Error(t, b.ValidateAddress(addr), addr)
+	}
+	_, _, err := b.HumanizeAddress(nil)
+	assert.Error(t, err)
+}
+
+func TestBech32AddressLengths(t *testing.T) {
+	b := NewBech32API("secret", 0)
+	for _, length := range []int{20, 32} {
+		canon := make([]byte, length)
+		for i := range canon {
+			canon[i] = byte(i + 1)
+		}
+		human, _, err := b.HumanizeAddress(canon)
+		require.NoError(t, err, length)
+		recover, _, err := b.CanonicalizeAddress(human)
+		require.NoError(t, err, length)
+		assert.Equal(t, canon, recover)
+	}
+
+	// anything else is rejected both ways
+	invalid := make([]byte, 25)
+	_, _, err := b.HumanizeAddress(invalid)
+	require.Error(t, err)
+	assert.Equal(t, "invalid canonical address length 25, expected one of [20 32]", err.Error())
+
+	unrestricted := Bech32API{Bech32Prefix: "secret"}
+	human, _, err := unrestricted.HumanizeAddress(invalid)
+	require.NoError(t, err)
+	_, _, err = b.CanonicalizeAddress(human)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid canonical address length 25")
+
+	// chains can restrict this further
+	b.AddressLengths = []int{20}
+	_, _, err = b.HumanizeAddress(make([]byte, 32))
+	require.Error(t, err)
+}
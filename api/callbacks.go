@@ -35,6 +35,7 @@ GoResult cQueryExternal_cgo(querier_t *ptr, uint64_t gas_limit, uint64_t *used_g
 import "C"
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -66,6 +67,10 @@ func recoverPanic(ret *C.GoResult) {
 		// TODO handle these cases on the Rust side in the first place
 		case "ErrorOutOfGas":
 			*ret = C.GoResult_OutOfGas
+		// raised by cosmwasm.Wasmer when the context of the call is done, to abort the contract.
+		// The Wasmer reports the cancellation itself, so there is nothing to log here.
+		case "cancelledCall":
+			*ret = C.GoResult_Panic
 		// Looks like this error is not treated specially upstream:
 		// https://github.com/cosmos/cosmos-sdk/blob/4ffabb65a5c07dbb7010da397535d10927d298c1/baseapp/baseapp.go#L818-L853
 		// but this needs to be periodically verified, in case they do start checking for this type
@@ -147,12 +152,15 @@ var iterator_vtable = C.Iterator_vtable{
 
 // contract: original pointer/struct referenced must live longer than C.DB struct
 // since this is only used internally, we can verify the code that this is the case
-func buildIterator(dbCounter uint64, it dbm.Iterator) C.iterator_t {
-	idx := storeIterator(dbCounter, it)
+func buildIterator(dbCounter uint64, it dbm.Iterator) (C.iterator_t, error) {
+	idx, err := storeIterator(dbCounter, it)
+	if err != nil {
+		return C.iterator_t{}, err
+	}
 	return C.iterator_t{
 		db_counter:     u64(dbCounter),
 		iterator_index: u64(idx),
-	}
+	}, nil
 }
 
 //export cGet
@@ -194,6 +202,11 @@ func cSet(ptr *C.db_t, gasMeter *C.gas_meter_t, usedGas *C.uint64_t, key C.Buffe
 
 	gm := *(*GasMeter)(unsafe.Pointer(gasMeter))
 	kv := *(*KVStore)(unsafe.Pointer(ptr))
+	if _, ok := kv.(readOnlyStore); ok {
+		// feed this back as a proper error rather than a panic
+		*errOut = allocateRust([]byte(types.ReadOnlyStoreError{Op: "set"}.Error()))
+		return C.GoResult_Other
+	}
 	k := receiveSlice(key)
 	v := receiveSlice(val)
 
@@ -215,6 +228,11 @@ func cDelete(ptr *C.db_t, gasMeter *C.gas_meter_t, usedGas *C.uint64_t, key C.Bu
 
 	gm := *(*GasMeter)(unsafe.Pointer(gasMeter))
 	kv := *(*KVStore)(unsafe.Pointer(ptr))
+	if _, ok := kv.(readOnlyStore); ok {
+		// feed this back as a proper error rather than a panic
+		*errOut = allocateRust([]byte(types.ReadOnlyStoreError{Op: "delete"}.Error()))
+		return C.GoResult_Other
+	}
 	k := receiveSlice(key)
 
 	gasBefore := gm.GasConsumed()
@@ -258,7 +276,14 @@ func cScan(ptr *C.db_t, gasMeter *C.gas_meter_t, usedGas *C.uint64_t, start C.Bu
 	gasAfter := gm.GasConsumed()
 	*usedGas = (C.uint64_t)(gasAfter - gasBefore)
 
-	out.state = buildIterator(state.IteratorStackID, iter)
+	ref, err := buildIterator(state.IteratorStackID, iter)
+	if err != nil {
+		// the iterator was never handed to the contract, so nobody else will close it
+		iter.Close()
+		*errOut = allocateRust([]byte(err.Error()))
+		return C.GoResult_Other
+	}
+	out.state = ref
 	out.vtable = iterator_vtable
 	return C.GoResult_Ok
 }
@@ -305,9 +330,19 @@ func cNext(ref C.iterator_t, gasMeter *C.gas_meter_t, usedGas *C.uint64_t, key *
 type HumanizeAddress func([]byte) (string, uint64, error)
 type CanonicalizeAddress func(string) ([]byte, uint64, error)
 
+// ContractCodeHash returns the code hash of the contract with the given canonical address,
+// and the gas used. It should return a types.NotFound error for unknown addresses.
+type ContractCodeHash func([]byte) (string, uint64, error)
+
 type GoAPI struct {
 	HumanAddress     HumanizeAddress
 	CanonicalAddress CanonicalizeAddress
+	// GetContractCodeHash is optional. It is not part of the api vtable yet, so contracts
+	// cannot call it until the enclave exposes it.
+	GetContractCodeHash ContractCodeHash
+	// BindContext is optional. If set, the *Context methods of cosmwasm.Wasmer run the call with
+	// the GoAPI it returns for the context of the call, so the callbacks can use it.
+	BindContext func(ctx context.Context) GoAPI
 }
 
 var api_vtable = C.GoApi_vtable{
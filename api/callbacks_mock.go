@@ -2,6 +2,8 @@
 
 package api
 
+import "context"
+
 //
 ///*
 //#include "bindings.h"
@@ -304,10 +306,18 @@ type KVStore interface {
 type HumanAddress func([]byte) (string, error)
 type CanonicalAddress func(string) ([]byte, error)
 
+// ContractCodeHash returns the code hash of the contract with the given canonical address,
+// and the gas used. It should return a types.NotFound error for unknown addresses.
+type ContractCodeHash func([]byte) (string, uint64, error)
+
 //
 type GoAPI struct {
-	HumanAddress     HumanAddress
-	CanonicalAddress CanonicalAddress
+	HumanAddress        HumanAddress
+	CanonicalAddress    CanonicalAddress
+	GetContractCodeHash ContractCodeHash
+	// BindContext is optional. If set, the *Context methods of cosmwasm.Wasmer run the call with
+	// the GoAPI it returns for the context of the call, so the callbacks can use it.
+	BindContext func(ctx context.Context) GoAPI
 }
 
 //
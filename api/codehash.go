@@ -0,0 +1,65 @@
+// +build !secretcli
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// InjectCodeHash looks up the code hash of the contract a smart query is sent to, and sets it
+// as the query's CallbackCodeHash. It returns the gas used by the lookup.
+// A contract without a code hash is an error, as the enclave could not decrypt the query.
+func InjectCodeHash(api *GoAPI, query *types.SmartQuery) (uint64, error) {
+	if api.GetContractCodeHash == nil {
+		return 0, fmt.Errorf("cannot query contract %s: no code hash lookup configured", query.ContractAddr)
+	}
+	canon, gasUsed, err := api.CanonicalAddress(query.ContractAddr)
+	if err != nil {
+		return gasUsed, err
+	}
+	hash, cost, err := api.GetContractCodeHash(canon)
+	gasUsed += cost
+	if err != nil {
+		return gasUsed, err
+	}
+	if hash == "" {
+		return gasUsed, fmt.Errorf("cannot query contract %s: it has no code hash", query.ContractAddr)
+	}
+	query.CallbackCodeHash = hash
+	return gasUsed, nil
+}
+
+// codeHashQuerier wraps a Querier, injecting the code hash into all smart queries
+type codeHashQuerier struct {
+	Querier
+	api     *GoAPI
+	usedGas uint64
+}
+
+var _ Querier = (*codeHashQuerier)(nil)
+
+// InjectCodeHashes returns a view of querier that fills the CallbackCodeHash of every smart query
+// with InjectCodeHash before dispatching it. The gas of the lookups is included in GasConsumed.
+func InjectCodeHashes(querier Querier, api *GoAPI) Querier {
+	return &codeHashQuerier{Querier: querier, api: api}
+}
+
+func (q *codeHashQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	if request.Wasm != nil && request.Wasm.Smart != nil {
+		// copy so the caller's request is left alone
+		smart := *request.Wasm.Smart
+		gasUsed, err := InjectCodeHash(q.api, &smart)
+		q.usedGas += gasUsed
+		if err != nil {
+			return nil, err
+		}
+		request.Wasm = &types.WasmQuery{Smart: &smart}
+	}
+	return q.Querier.Query(request, gasLimit)
+}
+
+func (q *codeHashQuerier) GasConsumed() uint64 {
+	return q.usedGas + q.Querier.GasConsumed()
+}
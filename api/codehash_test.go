@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// recordingQuerier keeps the last request it got
+type recordingQuerier struct {
+	last types.QueryRequest
+}
+
+func (q *recordingQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	q.last = request
+	return []byte(`{}`), nil
+}
+
+func (q *recordingQuerier) GasConsumed() uint64 {
+	return 7
+}
+
+func TestInjectCodeHash(t *testing.T) {
+	api := NewMockAPI()
+	api.GetContractCodeHash = MockCodeHashRegistry(map[string]string{"callee": "c0ffee"})
+
+	query := types.SmartQuery{ContractAddr: "callee", Msg: []byte(`{}`)}
+	gasUsed, err := InjectCodeHash(api, &query)
+	require.NoError(t, err)
+	assert.Equal(t, "c0ffee", query.CallbackCodeHash)
+	assert.Equal(t, CostCanonical+CostCodeHash, gasUsed)
+	bz, err := json.Marshal(query)
+	require.NoError(t, err)
+	assert.Equal(t, `{"contract_addr":"callee","callback_code_hash":"c0ffee","msg":"e30="}`, string(bz))
+
+	// unknown contract
+	query = types.SmartQuery{ContractAddr: "unknown", Msg: []byte(`{}`)}
+	_, err = InjectCodeHash(api, &query)
+	require.Error(t, err)
+	assert.IsType(t, types.NotFound{}, err)
+	assert.Empty(t, query.CallbackCodeHash)
+
+	// no lookup at all
+	api.GetContractCodeHash = nil
+	_, err = InjectCodeHash(api, &query)
+	require.EqualError(t, err, "cannot query contract unknown: no code hash lookup configured")
+}
+
+func TestInjectCodeHashes(t *testing.T) {
+	api := NewMockAPI()
+	api.GetContractCodeHash = MockCodeHashRegistry(map[string]string{"callee": "c0ffee"})
+	inner := &recordingQuerier{}
+	querier := InjectCodeHashes(inner, api)
+
+	smart := &types.SmartQuery{ContractAddr: "callee", Msg: []byte(`{}`)}
+	_, err := querier.Query(types.QueryRequest{Wasm: &types.WasmQuery{Smart: smart}}, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "c0ffee", inner.last.Wasm.Smart.CallbackCodeHash)
+	assert.Empty(t, smart.CallbackCodeHash)
+	assert.Equal(t, CostCanonical+CostCodeHash+7, querier.GasConsumed())
+
+	// other queries pass through untouched
+	raw := types.QueryRequest{Wasm: &types.WasmQuery{Raw: &types.RawQuery{ContractAddr: "callee", Key: []byte("k")}}}
+	_, err = querier.Query(raw, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, raw, inner.last)
+
+	// missing code hashes never reach the inner querier
+	inner.last = types.QueryRequest{}
+	_, err = querier.Query(types.QueryRequest{Wasm: &types.WasmQuery{Smart: &types.SmartQuery{ContractAddr: "unknown"}}}, 1000)
+	require.Error(t, err)
+	assert.Nil(t, inner.last.Wasm)
+}
@@ -0,0 +1,61 @@
+// +build !secretcli
+
+package api
+
+import (
+	"bytes"
+)
+
+// KeyValueChange is a difference between two stores, see DiffStores
+type KeyValueChange struct {
+	Key []byte
+	// Old is the value in the first store, nil if the key was added
+	Old []byte
+	// New is the value in the second store, nil if the key was removed
+	New []byte
+}
+
+// DiffStores compares two stores (e.g. the state of a contract before and after a migration).
+// It returns the keys only in b (added), only in a (removed) and with different values (changed),
+// each in ascending key order. Both stores are iterated in parallel, so they are never loaded into memory.
+func DiffStores(a, b KVStore) (added, removed, changed []KeyValueChange, err error) {
+	iterA := ExportState(a)
+	defer iterA.Close()
+	iterB := ExportState(b)
+	defer iterB.Close()
+
+	for iterA.Valid() || iterB.Valid() {
+		cmp := 0
+		switch {
+		case !iterA.Valid():
+			cmp = 1
+		case !iterB.Valid():
+			cmp = -1
+		default:
+			cmp = bytes.Compare(iterA.Key(), iterB.Key())
+		}
+
+		switch {
+		case cmp < 0:
+			removed = append(removed, KeyValueChange{Key: iterA.Key(), Old: iterA.Value()})
+			iterA.Next()
+		case cmp > 0:
+			added = append(added, KeyValueChange{Key: iterB.Key(), New: iterB.Value()})
+			iterB.Next()
+		default:
+			if !bytes.Equal(iterA.Value(), iterB.Value()) {
+				changed = append(changed, KeyValueChange{Key: iterA.Key(), Old: iterA.Value(), New: iterB.Value()})
+			}
+			iterA.Next()
+			iterB.Next()
+		}
+	}
+
+	if err := iterA.Error(); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := iterB.Error(); err != nil {
+		return nil, nil, nil, err
+	}
+	return added, removed, changed, nil
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStores(t *testing.T) {
+	before := NewLookup(NewMockGasMeter(100000000))
+	before.Set([]byte("balance"), []byte("100"))
+	before.Set([]byte("config"), []byte("v1"))
+	before.Set([]byte("owner"), []byte("alice"))
+	before.Set([]byte("zombie"), []byte("x"))
+
+	after := NewLookup(NewMockGasMeter(100000000))
+	after.Set([]byte("balance"), []byte("100"))
+	after.Set([]byte("config"), []byte("v2"))
+	after.Set([]byte("migrated"), []byte("true"))
+	after.Set([]byte("owner"), []byte("alice"))
+	after.Set([]byte("aaa"), []byte("first"))
+
+	added, removed, changed, err := DiffStores(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, []KeyValueChange{
+		{Key: []byte("aaa"), New: []byte("first")},
+		{Key: []byte("migrated"), New: []byte("true")},
+	}, added)
+	assert.Equal(t, []KeyValueChange{{Key: []byte("zombie"), Old: []byte("x")}}, removed)
+	assert.Equal(t, []KeyValueChange{{Key: []byte("config"), Old: []byte("v1"), New: []byte("v2")}}, changed)
+
+	// the other way around
+	added, removed, _, err = DiffStores(after, before)
+	require.NoError(t, err)
+	assert.Len(t, added, 1)
+	assert.Len(t, removed, 2)
+
+	// identical stores
+	added, removed, changed, err = DiffStores(before, before)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+	assert.Empty(t, changed)
+}
@@ -0,0 +1,86 @@
+// +build !secretcli
+
+package api
+
+import (
+	"bytes"
+	"fmt"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+// ExportState returns an iterator over the entire state of a contract, e.g. for a genesis export.
+// Keys are returned in ascending order, so the export is reproducible. An iterator of the store
+// returning keys out of order stops and reports it in Error(), rather than silently producing
+// a non-deterministic dump.
+//
+// This is a trusted path, no gas is charged by the VM. The store should not be wrapped in a
+// gas metering store either, as an export may touch the whole state.
+func ExportState(store KVStore) dbm.Iterator {
+	return &exportIterator{source: store.Iterator(nil, nil)}
+}
+
+// KeyValue is an entry of a store
+type KeyValue struct {
+	Key   []byte
+	Value []byte
+}
+
+// PrefixScan returns all entries of store under prefix, in ascending key order and with the
+// prefix stripped from the keys, e.g. to list the config of a contract for admin tooling.
+// No entries is an empty list, not an error. Like ExportState, this is a trusted path without gas.
+func PrefixScan(store KVStore, prefix []byte) ([]KeyValue, error) {
+	iter := ExportState(PrefixStore(store, prefix))
+	defer iter.Close()
+	res := []KeyValue{}
+	for ; iter.Valid(); iter.Next() {
+		res = append(res, KeyValue{Key: iter.Key(), Value: iter.Value()})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+type exportIterator struct {
+	source dbm.Iterator
+	last   []byte
+	err    error
+}
+
+var _ dbm.Iterator = (*exportIterator)(nil)
+
+func (i *exportIterator) Domain() ([]byte, []byte) {
+	return i.source.Domain()
+}
+
+func (i *exportIterator) Valid() bool {
+	return i.err == nil && i.source.Valid()
+}
+
+func (i *exportIterator) Next() {
+	i.last = i.source.Key()
+	i.source.Next()
+	if i.source.Valid() && bytes.Compare(i.source.Key(), i.last) <= 0 {
+		i.err = fmt.Errorf("export keys out of order: %X after %X", i.source.Key(), i.last)
+	}
+}
+
+func (i *exportIterator) Key() []byte {
+	return i.source.Key()
+}
+
+func (i *exportIterator) Value() []byte {
+	return i.source.Value()
+}
+
+func (i *exportIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.source.Error()
+}
+
+func (i *exportIterator) Close() error {
+	return i.source.Close()
+}
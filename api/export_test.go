@@ -0,0 +1,81 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestExportState(t *testing.T) {
+	store := NewLookup(NewMockGasMeter(100000000))
+	for _, key := range []string{"zebra", "apple", "mango", "banana", "apricot"} {
+		store.Set([]byte(key), []byte("value of "+key))
+	}
+
+	var keys []string
+	iter := ExportState(store)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, string(iter.Key()))
+		assert.Equal(t, "value of "+string(iter.Key()), string(iter.Value()))
+	}
+	require.NoError(t, iter.Error())
+	assert.Equal(t, []string{"apple", "apricot", "banana", "mango", "zebra"}, keys)
+}
+
+// unorderedStore is broken, it iterates backwards
+type unorderedStore struct {
+	*Lookup
+}
+
+func (s unorderedStore) Iterator(start, end []byte) dbm.Iterator {
+	return s.Lookup.ReverseIterator(start, end)
+}
+
+func TestExportStateDetectsUnorderedKeys(t *testing.T) {
+	store := NewLookup(NewMockGasMeter(100000000))
+	store.Set([]byte("a"), []byte("1"))
+	store.Set([]byte("b"), []byte("2"))
+
+	var keys []string
+	iter := ExportState(unorderedStore{store})
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	assert.Equal(t, []string{"b"}, keys)
+	require.Error(t, iter.Error())
+	assert.Contains(t, iter.Error().Error(), "out of order")
+}
+
+func TestPrefixScan(t *testing.T) {
+	store := NewLookup(NewMockGasMeter(100000000))
+	for _, key := range []string{"config/name", "config/admin", "config/fees/send", "config/fees/swap", "configuration", "state/count"} {
+		store.Set([]byte(key), []byte("value of "+key))
+	}
+
+	entries, err := PrefixScan(store, []byte("config/"))
+	require.NoError(t, err)
+	var keys []string
+	for _, entry := range entries {
+		keys = append(keys, string(entry.Key))
+		assert.Equal(t, "value of config/"+string(entry.Key), string(entry.Value))
+	}
+	assert.Equal(t, []string{"admin", "fees/send", "fees/swap", "name"}, keys)
+
+	// nested prefixes only see their own keys
+	entries, err = PrefixScan(store, []byte("config/fees/"))
+	require.NoError(t, err)
+	assert.Equal(t, []KeyValue{
+		{Key: []byte("send"), Value: []byte("value of config/fees/send")},
+		{Key: []byte("swap"), Value: []byte("value of config/fees/swap")},
+	}, entries)
+
+	// nothing under the prefix
+	entries, err = PrefixScan(store, []byte("missing/"))
+	require.NoError(t, err)
+	assert.NotNil(t, entries)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,90 @@
+// +build !secretcli
+
+package api
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ImportCursor records the progress of a state import, see StateImporter.
+// Persist it on every flush to be able to resume an interrupted import.
+type ImportCursor struct {
+	// Imported is the number of entries written so far
+	Imported uint64
+	// LastKey is the last key written, all keys up to (and including) it are already imported
+	LastKey []byte
+}
+
+// StateImporter streams contract state (e.g. from a genesis file) into a store, without holding
+// it all in memory. Entries must be added in ascending key order, just as ExportState produces them.
+//
+// Every flushEvery entries, flush is called with the current cursor, so the caller can commit
+// the writes so far and persist the cursor. To resume an interrupted import, create a new
+// StateImporter with the last persisted cursor and add the full stream again: entries up to
+// the cursor are skipped. The resulting state does not depend on where the flushes happened.
+type StateImporter struct {
+	store      KVStore
+	flushEvery uint64
+	flush      func(ImportCursor) error
+	cursor     ImportCursor
+	pending    uint64
+	// set while we skip the entries imported before a resume
+	skipping bool
+}
+
+// NewStateImporter creates an importer writing to store, resuming from cursor (use the zero
+// value for a fresh import). flush may be nil, and flushEvery 0 means flushing only on Finish.
+func NewStateImporter(store KVStore, cursor ImportCursor, flushEvery uint64, flush func(ImportCursor) error) *StateImporter {
+	return &StateImporter{
+		store:      store,
+		flushEvery: flushEvery,
+		flush:      flush,
+		cursor:     cursor,
+		skipping:   cursor.LastKey != nil,
+	}
+}
+
+// Add imports one entry. Keys must be strictly ascending, including across a resume.
+func (i *StateImporter) Add(key, value []byte) error {
+	if len(key) == 0 {
+		return fmt.Errorf("Null/Empty argument: key")
+	}
+	if last := i.cursor.LastKey; last != nil && bytes.Compare(key, last) <= 0 {
+		if i.skipping {
+			// already imported before the resume
+			return nil
+		}
+		return fmt.Errorf("import keys out of order: %X after %X", key, last)
+	}
+	i.skipping = false
+	i.store.Set(key, value)
+	i.cursor.Imported++
+	i.cursor.LastKey = append([]byte{}, key...)
+	i.pending++
+	if i.flushEvery > 0 && i.pending >= i.flushEvery {
+		return i.doFlush()
+	}
+	return nil
+}
+
+// Finish flushes the remaining entries
+func (i *StateImporter) Finish() error {
+	if i.pending == 0 {
+		return nil
+	}
+	return i.doFlush()
+}
+
+// Cursor returns the progress so far
+func (i *StateImporter) Cursor() ImportCursor {
+	return i.cursor
+}
+
+func (i *StateImporter) doFlush() error {
+	i.pending = 0
+	if i.flush == nil {
+		return nil
+	}
+	return i.flush(i.cursor)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type entry struct {
+	key, value []byte
+}
+
+func genesisEntries(n int) []entry {
+	entries := make([]entry, n)
+	for i := range entries {
+		entries[i] = entry{key: []byte(fmt.Sprintf("key%03d", i)), value: []byte(fmt.Sprintf("value%d", i))}
+	}
+	return entries
+}
+
+func dumpState(t *testing.T, store KVStore) []string {
+	var res []string
+	iter := ExportState(store)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		res = append(res, string(iter.Key())+"="+string(iter.Value()))
+	}
+	require.NoError(t, iter.Error())
+	return res
+}
+
+func TestStateImporterResume(t *testing.T) {
+	entries := genesisEntries(10)
+
+	// single shot
+	expected := NewLookup(NewMockGasMeter(100000000))
+	importer := NewStateImporter(expected, ImportCursor{}, 0, nil)
+	for _, e := range entries {
+		require.NoError(t, importer.Add(e.key, e.value))
+	}
+	require.NoError(t, importer.Finish())
+	assert.Equal(t, uint64(10), importer.Cursor().Imported)
+
+	// first chunk, interrupted after the second flush
+	store := NewLookup(NewMockGasMeter(100000000))
+	var saved ImportCursor
+	flushes := 0
+	importer = NewStateImporter(store, ImportCursor{}, 3, func(cursor ImportCursor) error {
+		saved = cursor
+		flushes++
+		return nil
+	})
+	for _, e := range entries {
+		require.NoError(t, importer.Add(e.key, e.value))
+		if flushes == 2 {
+			break
+		}
+	}
+	assert.Equal(t, ImportCursor{Imported: 6, LastKey: []byte("key005")}, saved)
+
+	// second chunk resumes, replaying the whole stream
+	var final ImportCursor
+	importer = NewStateImporter(store, saved, 4, func(cursor ImportCursor) error {
+		final = cursor
+		return nil
+	})
+	for _, e := range entries {
+		require.NoError(t, importer.Add(e.key, e.value))
+	}
+	require.NoError(t, importer.Finish())
+	assert.Equal(t, ImportCursor{Imported: 10, LastKey: []byte("key009")}, final)
+
+	assert.Equal(t, dumpState(t, expected), dumpState(t, store))
+}
+
+func TestStateImporterRejectsUnsorted(t *testing.T) {
+	store := NewLookup(NewMockGasMeter(100000000))
+	importer := NewStateImporter(store, ImportCursor{}, 0, nil)
+	require.NoError(t, importer.Add([]byte("b"), []byte("1")))
+	err := importer.Add([]byte("a"), []byte("2"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "out of order")
+	err = importer.Add([]byte("b"), []byte("3"))
+	require.Error(t, err)
+	err = importer.Add(nil, []byte("4"))
+	require.Error(t, err)
+
+	// after a resume, old keys are skipped, but only until the first new one
+	importer = NewStateImporter(store, importer.Cursor(), 0, nil)
+	require.NoError(t, importer.Add([]byte("a"), []byte("ignored")))
+	require.NoError(t, importer.Add([]byte("c"), []byte("5")))
+	require.Error(t, importer.Add([]byte("a"), []byte("6")))
+	assert.Nil(t, store.Get([]byte("a")))
+}
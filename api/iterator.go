@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	dbm "github.com/tendermint/tm-db"
 	"sync"
 )
@@ -13,6 +14,38 @@ type frame []dbm.Iterator
 var iteratorStack = make(map[uint64]frame, 10)
 var iteratorStackMutex sync.Mutex
 
+// iteratorLimits holds the maximum number of iterators of the contract calls that have one,
+// indexed like iteratorStack. It is guarded by iteratorStackMutex.
+var iteratorLimits = make(map[uint64]uint64)
+
+// iteratorLimitStore carries the iterator limit of a call to the api functions, see LimitIterators
+type iteratorLimitStore struct {
+	KVStore
+	max uint64
+}
+
+// LimitIterators returns store, setting the maximum number of iterators a contract call
+// using it may open (0 for no limit). Contracts cannot close iterators themselves, they are
+// all closed when the call ends, so this bounds the host resources a single call can hold on to.
+// The limit is only found on the store passed to the api functions, so wrap it last.
+func LimitIterators(store KVStore, max uint64) KVStore {
+	if max == 0 || store == nil {
+		return store
+	}
+	return iteratorLimitStore{KVStore: store, max: max}
+}
+
+// setIteratorLimit applies the iterator limit of store (see LimitIterators) to the call counter
+func setIteratorLimit(counter uint64, store KVStore) {
+	limited, ok := store.(iteratorLimitStore)
+	if !ok {
+		return
+	}
+	iteratorStackMutex.Lock()
+	defer iteratorStackMutex.Unlock()
+	iteratorLimits[counter] = limited.max
+}
+
 // this is a global counter when we create DBs
 var dbCounter uint64
 var dbCounterMutex sync.Mutex
@@ -33,6 +66,7 @@ func popFrame(counter uint64) frame {
 
 	remove := iteratorStack[counter]
 	delete(iteratorStack, counter)
+	delete(iteratorLimits, counter)
 	return remove
 }
 
@@ -48,14 +82,18 @@ func endContract(counter uint64) {
 
 // storeIterator will add this to the end of the latest stack and return a reference to it.
 // We start counting with 1, so the 0 value is flagged as an error. This means we must
-// remember to do idx-1 when retrieving
-func storeIterator(dbCounter uint64, it dbm.Iterator) uint64 {
+// remember to do idx-1 when retrieving.
+// It returns an error if the contract already opened as many iterators as allowed.
+func storeIterator(dbCounter uint64, it dbm.Iterator) (uint64, error) {
 	iteratorStackMutex.Lock()
 	defer iteratorStackMutex.Unlock()
 
+	if maxIterators := iteratorLimits[dbCounter]; maxIterators != 0 && uint64(len(iteratorStack[dbCounter])) >= maxIterators {
+		return 0, fmt.Errorf("too many open iterators, a contract call may open at most %d", maxIterators)
+	}
 	frame := append(iteratorStack[dbCounter], it)
 	iteratorStack[dbCounter] = frame
-	return uint64(len(frame))
+	return uint64(len(frame)), nil
 }
 
 // retrieveIterator will recover an iterator based on index. This ensures it will not be garbage collected.
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
 
 	"github.com/CosmWasm/go-cosmwasm/types"
 )
@@ -144,3 +145,65 @@ func TestQueueIteratorRaces(t *testing.T) {
 	// when they finish, we should have popped everything off the stack
 	assert.Equal(t, len(iteratorStack), 0)
 }
+
+// closeCounter counts how often the wrapped iterator was closed
+type closeCounter struct {
+	dbm.Iterator
+	closed *int
+}
+
+func (c closeCounter) Close() error {
+	*c.closed++
+	return c.Iterator.Close()
+}
+
+func TestIteratorLimit(t *testing.T) {
+	store := NewLookup(NewMockGasMeter(100000000))
+	counter := startContract()
+	defer endContract(counter)
+	setIteratorLimit(counter, LimitIterators(store, 2))
+
+	for i := uint64(1); i <= 2; i++ {
+		idx, err := storeIterator(counter, store.Iterator(nil, nil))
+		require.NoError(t, err)
+		assert.Equal(t, i, idx)
+	}
+	_, err := storeIterator(counter, store.Iterator(nil, nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "may open at most 2")
+
+	// the limit is per call
+	other := startContract()
+	defer endContract(other)
+	for i := 0; i < 3; i++ {
+		_, err = storeIterator(other, store.Iterator(nil, nil))
+		require.NoError(t, err)
+	}
+	strict := startContract()
+	defer endContract(strict)
+	setIteratorLimit(strict, LimitIterators(store, 1))
+	_, err = storeIterator(strict, store.Iterator(nil, nil))
+	require.NoError(t, err)
+	_, err = storeIterator(strict, store.Iterator(nil, nil))
+	assert.Contains(t, err.Error(), "may open at most 1")
+
+	// no limit leaves the store as it is
+	assert.Equal(t, KVStore(store), LimitIterators(store, 0))
+}
+
+func TestEndContractClosesIterators(t *testing.T) {
+	store := NewLookup(NewMockGasMeter(100000000))
+	store.Set([]byte("foo"), []byte("bar"))
+
+	closed := 0
+	counter := startContract()
+	for i := 0; i < 3; i++ {
+		_, err := storeIterator(counter, closeCounter{store.Iterator(nil, nil), &closed})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, closed)
+
+	endContract(counter)
+	assert.Equal(t, 3, closed)
+	assert.Empty(t, popFrame(counter))
+}
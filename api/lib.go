@@ -7,7 +7,6 @@ package api
 import "C"
 
 import (
-	"fmt"
 	"syscall"
 
 	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
@@ -118,12 +117,13 @@ func Instantiate(
 	defer freeAfterSend(id)
 	p := sendSlice(params)
 	defer freeAfterSend(p)
-	m := sendSlice(msg)
-	defer freeAfterSend(m)
+	m := sendMsg(msg)
+	defer freeMsg(m, msg)
 
 	// set up a new stack frame to handle iterators
 	counter := startContract()
 	defer endContract(counter)
+	setIteratorLimit(counter, store)
 
 	dbState := buildDBState(store, counter)
 	db := buildDB(&dbState, gasMeter)
@@ -155,12 +155,13 @@ func Handle(
 	defer freeAfterSend(id)
 	p := sendSlice(params)
 	defer freeAfterSend(p)
-	m := sendSlice(msg)
-	defer freeAfterSend(m)
+	m := sendMsg(msg)
+	defer freeMsg(m, msg)
 
 	// set up a new stack frame to handle iterators
 	counter := startContract()
 	defer endContract(counter)
+	setIteratorLimit(counter, store)
 
 	dbState := buildDBState(store, counter)
 	db := buildDB(&dbState, gasMeter)
@@ -192,12 +193,13 @@ func Migrate(
 	defer freeAfterSend(id)
 	p := sendSlice(params)
 	defer freeAfterSend(p)
-	m := sendSlice(msg)
-	defer freeAfterSend(m)
+	m := sendMsg(msg)
+	defer freeMsg(m, msg)
 
 	// set up a new stack frame to handle iterators
 	counter := startContract()
 	defer endContract(counter)
+	setIteratorLimit(counter, store)
 
 	dbState := buildDBState(store, counter)
 	db := buildDB(&dbState, gasMeter)
@@ -226,14 +228,16 @@ func Query(
 ) ([]byte, uint64, error) {
 	id := sendSlice(code_id)
 	defer freeAfterSend(id)
-	m := sendSlice(msg)
-	defer freeAfterSend(m)
+	m := sendMsg(msg)
+	defer freeMsg(m, msg)
 
 	// set up a new stack frame to handle iterators
 	counter := startContract()
 	defer endContract(counter)
+	setIteratorLimit(counter, store)
 
-	dbState := buildDBState(store, counter)
+	// queries must never write
+	dbState := buildDBState(ReadOnlyStore(store), counter)
 	db := buildDB(&dbState, gasMeter)
 	a := buildAPI(api)
 	q := buildQuerier(querier)
@@ -290,5 +294,15 @@ func errorWithMessage(err error, b C.Buffer) error {
 	if msg == nil {
 		return err
 	}
-	return fmt.Errorf("%s", string(msg))
+	return vmError(string(msg))
+}
+
+// vmError turns an error message of the VM into an error, telling host errors from contract errors.
+// The backtrace of contract errors is kept outside of the message.
+func vmError(raw string) error {
+	msg := types.NormalizeVMError(raw)
+	if types.IsHostErrorMessage(msg) {
+		return types.HostError{Msg: msg}
+	}
+	return types.VMError{Msg: msg, Backtrace: types.VMBacktrace(raw)}
 }
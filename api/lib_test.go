@@ -604,3 +604,21 @@ func TestCustomReflectQuerier(t *testing.T) {
 	err = json.Unmarshal(qres.Ok, &response)
 	require.Equal(t, response.Msg, "SMALL FRYS :)")
 }
+
+func TestVMErrorClassification(t *testing.T) {
+	// a disk read failure while loading a module
+	err := vmError("Error opening Wasm file for reading: Input/output error (os error 5)")
+	require.True(t, types.IsHostError(err))
+	assert.Equal(t, types.HostError{Msg: "Error opening Wasm file for reading: os error 5"}, err)
+
+	err = vmError("Error executing Wasm: RuntimeError: unreachable")
+	require.False(t, types.IsHostError(err))
+	assert.EqualError(t, err, "Error executing Wasm: RuntimeError: unreachable")
+
+	// a trap keeps its backtrace, but only outside of the message
+	err = vmError("Error executing Wasm: RuntimeError: unreachable\nstack backtrace:\n   0: go_cosmwasm::handle\n")
+	assert.Equal(t, types.VMError{
+		Msg:       "Error executing Wasm: RuntimeError: unreachable",
+		Backtrace: "stack backtrace:\n   0: go_cosmwasm::handle",
+	}, err)
+}
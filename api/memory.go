@@ -7,7 +7,10 @@ package api
 */
 import "C"
 
-import "unsafe"
+import (
+	"runtime"
+	"unsafe"
+)
 
 func allocateRust(data []byte) C.Buffer {
 	var ret C.Buffer
@@ -49,6 +52,32 @@ func sendSlice(s []byte) C.Buffer {
 	}
 }
 
+// zeroCopyThreshold is the size from which sendMsg passes messages without copying them
+const zeroCopyThreshold = 64 * 1024
+
+// sendMsg is like sendSlice, but messages of at least zeroCopyThreshold bytes are not copied:
+// the buffer points straight into s. This is safe because Rust only reads the message during
+// the call (and copies it into the enclave), it never keeps or frees it.
+// The result must be released with freeMsg(b, s), which also keeps s alive until the call returns.
+func sendMsg(s []byte) C.Buffer {
+	if len(s) < zeroCopyThreshold {
+		return sendSlice(s)
+	}
+	return C.Buffer{
+		ptr: u8_ptr(unsafe.Pointer(&s[0])),
+		len: usize(len(s)),
+		cap: usize(len(s)),
+	}
+}
+
+// freeMsg releases a buffer created by sendMsg(s)
+func freeMsg(b C.Buffer, s []byte) {
+	if len(s) < zeroCopyThreshold {
+		freeAfterSend(b)
+	}
+	runtime.KeepAlive(s)
+}
+
 // Take an owned vector that was passed to us, copy it, and then free it on the Rust side.
 // This should only be used for vectors that will never be observed again on the Rust side
 func receiveVector(b C.Buffer) []byte {
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendMsg(t *testing.T) {
+	// small messages are copied
+	small := []byte("small message")
+	b := sendMsg(small)
+	small[0] = 'S'
+	assert.Equal(t, []byte("small message"), receiveSlice(b))
+	freeMsg(b, small)
+
+	// large ones are passed as they are
+	large := bytes.Repeat([]byte{'a'}, zeroCopyThreshold)
+	b = sendMsg(large)
+	large[0] = 'b'
+	recv := receiveSlice(b)
+	assert.Equal(t, byte('b'), recv[0])
+	assert.Equal(t, large, recv)
+	freeMsg(b, large)
+
+	// nil stays nil
+	b = sendMsg(nil)
+	assert.True(t, bufIsNil(b))
+	freeMsg(b, nil)
+}
+
+func BenchmarkSendMsg1MB(b *testing.B) {
+	msg := bytes.Repeat([]byte{'a'}, 1024*1024)
+	b.SetBytes(int64(len(msg)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := sendMsg(msg)
+		freeMsg(buf, msg)
+	}
+}
+
+func BenchmarkSendSlice1MB(b *testing.B) {
+	msg := bytes.Repeat([]byte{'a'}, 1024*1024)
+	b.SetBytes(int64(len(msg)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := sendSlice(msg)
+		freeAfterSend(buf)
+	}
+}
@@ -206,6 +206,23 @@ func NewMockAPI() *GoAPI {
 	}
 }
 
+const CostCodeHash uint64 = 330
+
+// MockCodeHashRegistry answers GetContractCodeHash from the given code hashes by human address
+func MockCodeHashRegistry(hashes map[string]string) ContractCodeHash {
+	return func(canon []byte) (string, uint64, error) {
+		human, _, err := MockHumanAddress(canon)
+		if err != nil {
+			return "", 0, err
+		}
+		hash, ok := hashes[human]
+		if !ok {
+			return "", CostCodeHash, types.NotFound{Kind: "contract " + human}
+		}
+		return hash, CostCodeHash, nil
+	}
+}
+
 func TestMockApi(t *testing.T) {
 	human := "foobar"
 	canon, cost, err := MockCanonicalAddress(human)
@@ -219,6 +236,27 @@ func TestMockApi(t *testing.T) {
 	assert.Equal(t, CostHuman, cost)
 }
 
+func TestMockCodeHashRegistry(t *testing.T) {
+	api := NewMockAPI()
+	api.GetContractCodeHash = MockCodeHashRegistry(map[string]string{"contract": "c0ffee"})
+
+	canon, _, err := api.CanonicalAddress("contract")
+	require.NoError(t, err)
+	hash, cost, err := api.GetContractCodeHash(canon)
+	require.NoError(t, err)
+	assert.Equal(t, "c0ffee", hash)
+	assert.Equal(t, CostCodeHash, cost)
+
+	// unknown contracts still cost gas
+	canon, _, err = api.CanonicalAddress("unknown")
+	require.NoError(t, err)
+	_, cost, err = api.GetContractCodeHash(canon)
+	require.Error(t, err)
+	assert.IsType(t, types.NotFound{}, err)
+	assert.Equal(t, "not found: contract unknown", err.Error())
+	assert.Equal(t, CostCodeHash, cost)
+}
+
 /**** MockQuerier ****/
 
 const DEFAULT_QUERIER_GAS_LIMIT = 1_000_000
@@ -257,6 +295,9 @@ func (q MockQuerier) Query(request types.QueryRequest, _gasLimit uint64) ([]byte
 	if request.Staking != nil {
 		return nil, types.UnsupportedRequest{"staking"}
 	}
+	if request.Distribution != nil {
+		return nil, types.UnsupportedRequest{"distribution"}
+	}
 	if request.Wasm != nil {
 		return nil, types.UnsupportedRequest{"wasm"}
 	}
@@ -0,0 +1,126 @@
+// +build !secretcli
+
+package api
+
+import (
+	dbm "github.com/tendermint/tm-db"
+)
+
+// prefixStore wraps a parent KVStore, transparently prefixing all keys
+type prefixStore struct {
+	parent KVStore
+	prefix []byte
+}
+
+var _ KVStore = prefixStore{}
+
+// PrefixStore returns a KVStore where every key is prefixed with prefix before it reaches
+// the parent store. Iterators only cover keys under the prefix and return them with the prefix stripped.
+// This lets many contracts share one underlying store, keyed by e.g. their address.
+func PrefixStore(parent KVStore, prefix []byte) KVStore {
+	return prefixStore{
+		parent: parent,
+		prefix: append([]byte{}, prefix...),
+	}
+}
+
+func (s prefixStore) key(key []byte) []byte {
+	res := make([]byte, len(s.prefix)+len(key))
+	copy(res, s.prefix)
+	copy(res[len(s.prefix):], key)
+	return res
+}
+
+func (s prefixStore) Get(key []byte) []byte {
+	return s.parent.Get(s.key(key))
+}
+
+func (s prefixStore) Set(key, value []byte) {
+	s.parent.Set(s.key(key), value)
+}
+
+func (s prefixStore) Delete(key []byte) {
+	s.parent.Delete(s.key(key))
+}
+
+func (s prefixStore) Iterator(start, end []byte) dbm.Iterator {
+	pstart, pend := s.domain(start, end)
+	return newPrefixIterator(s.prefix, start, end, s.parent.Iterator(pstart, pend))
+}
+
+func (s prefixStore) ReverseIterator(start, end []byte) dbm.Iterator {
+	pstart, pend := s.domain(start, end)
+	return newPrefixIterator(s.prefix, start, end, s.parent.ReverseIterator(pstart, pend))
+}
+
+// domain maps the (start, end) range onto the parent store. A nil end is the end of the prefix range.
+func (s prefixStore) domain(start, end []byte) ([]byte, []byte) {
+	pstart := s.key(start)
+	var pend []byte
+	if end == nil {
+		pend = prefixEnd(s.prefix)
+	} else {
+		pend = s.key(end)
+	}
+	return pstart, pend
+}
+
+// prefixEnd returns the smallest key greater than all keys with the given prefix,
+// or nil if there is none (prefix is empty or all 0xff)
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// prefixIterator strips the prefix from all keys of the parent iterator
+type prefixIterator struct {
+	prefix []byte
+	start  []byte
+	end    []byte
+	source dbm.Iterator
+}
+
+var _ dbm.Iterator = (*prefixIterator)(nil)
+
+func newPrefixIterator(prefix, start, end []byte, source dbm.Iterator) *prefixIterator {
+	return &prefixIterator{
+		prefix: prefix,
+		start:  start,
+		end:    end,
+		source: source,
+	}
+}
+
+func (i *prefixIterator) Domain() ([]byte, []byte) {
+	return i.start, i.end
+}
+
+func (i *prefixIterator) Valid() bool {
+	return i.source.Valid()
+}
+
+func (i *prefixIterator) Next() {
+	i.source.Next()
+}
+
+func (i *prefixIterator) Key() []byte {
+	return i.source.Key()[len(i.prefix):]
+}
+
+func (i *prefixIterator) Value() []byte {
+	return i.source.Value()
+}
+
+func (i *prefixIterator) Error() error {
+	return i.source.Error()
+}
+
+func (i *prefixIterator) Close() error {
+	return i.source.Close()
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixStoreGetSet(t *testing.T) {
+	parent := NewLookup(NewMockGasMeter(100000000))
+	store := PrefixStore(parent, []byte("contract1"))
+	other := PrefixStore(parent, []byte("contract2"))
+
+	store.Set([]byte("foo"), []byte("bar"))
+	assert.Equal(t, []byte("bar"), store.Get([]byte("foo")))
+	assert.Equal(t, []byte("bar"), parent.Get([]byte("contract1foo")))
+	assert.Nil(t, other.Get([]byte("foo")))
+
+	store.Delete([]byte("foo"))
+	assert.Nil(t, store.Get([]byte("foo")))
+	assert.Nil(t, parent.Get([]byte("contract1foo")))
+}
+
+func TestPrefixStoreIterator(t *testing.T) {
+	parent := NewLookup(NewMockGasMeter(100000000))
+	parent.Set([]byte("a1"), []byte("outside"))
+	parent.Set([]byte("c1"), []byte("outside"))
+	store := PrefixStore(parent, []byte("b"))
+	store.Set([]byte("1"), []byte("one"))
+	store.Set([]byte("2"), []byte("two"))
+	store.Set([]byte("3"), []byte("three"))
+
+	collect := func(start, end []byte, reverse bool) []string {
+		var res []string
+		open := store.Iterator
+		if reverse {
+			open = store.ReverseIterator
+		}
+		iter := open(start, end)
+		defer iter.Close()
+		for ; iter.Valid(); iter.Next() {
+			res = append(res, string(iter.Key())+"="+string(iter.Value()))
+		}
+		require.NoError(t, iter.Error())
+		return res
+	}
+
+	assert.Equal(t, []string{"1=one", "2=two", "3=three"}, collect(nil, nil, false))
+	assert.Equal(t, []string{"3=three", "2=two", "1=one"}, collect(nil, nil, true))
+	assert.Equal(t, []string{"2=two"}, collect([]byte("2"), []byte("3"), false))
+	assert.Equal(t, []string{"3=three", "2=two"}, collect([]byte("2"), nil, true))
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("c"), prefixEnd([]byte("b")))
+	assert.Equal(t, []byte{0x01, 0x03}, prefixEnd([]byte{0x01, 0x02, 0xff}))
+	assert.Nil(t, prefixEnd([]byte{0xff, 0xff}))
+	assert.Nil(t, prefixEnd(nil))
+}
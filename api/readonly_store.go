@@ -0,0 +1,31 @@
+// +build !secretcli
+
+package api
+
+import (
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// readOnlyStore wraps a KVStore, rejecting all writes
+type readOnlyStore struct {
+	KVStore
+}
+
+var _ KVStore = readOnlyStore{}
+
+// ReadOnlyStore returns a view of store that panics with a types.ReadOnlyStoreError on Set and Delete.
+// Query uses it, so the query entry point can never modify the contract state.
+func ReadOnlyStore(store KVStore) KVStore {
+	if _, ok := store.(readOnlyStore); ok {
+		return store
+	}
+	return readOnlyStore{store}
+}
+
+func (s readOnlyStore) Set(key, value []byte) {
+	panic(types.ReadOnlyStoreError{Op: "set"})
+}
+
+func (s readOnlyStore) Delete(key []byte) {
+	panic(types.ReadOnlyStoreError{Op: "delete"})
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+func TestReadOnlyStore(t *testing.T) {
+	store := NewLookup(NewMockGasMeter(100000000))
+	store.Set([]byte("foo"), []byte("bar"))
+	ro := ReadOnlyStore(store)
+
+	// reads go through
+	assert.Equal(t, []byte("bar"), ro.Get([]byte("foo")))
+	iter := ro.Iterator(nil, nil)
+	assert.True(t, iter.Valid())
+	assert.Equal(t, []byte("foo"), iter.Key())
+	iter.Close()
+
+	// writes fail with a clear error, and leave the store untouched
+	assert.PanicsWithValue(t, types.ReadOnlyStoreError{Op: "set"}, func() {
+		ro.Set([]byte("foo"), []byte("baz"))
+	})
+	assert.PanicsWithValue(t, types.ReadOnlyStoreError{Op: "delete"}, func() {
+		ro.Delete([]byte("foo"))
+	})
+	assert.Equal(t, []byte("bar"), store.Get([]byte("foo")))
+	assert.Equal(t, "cannot set in a query: storage is read-only", types.ReadOnlyStoreError{Op: "set"}.Error())
+
+	// wrapping twice is a no-op
+	assert.Equal(t, ro, ReadOnlyStore(ro))
+}
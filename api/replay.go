@@ -0,0 +1,519 @@
+// +build !secretcli
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// The kinds of host calls in a ReplayLog
+const (
+	OpGet              = "get"
+	OpSet              = "set"
+	OpDelete           = "delete"
+	OpIterator         = "iterator"
+	OpReverseIterator  = "reverse_iterator"
+	OpNext             = "next"
+	OpQuery            = "query"
+	OpHumanAddress     = "human_address"
+	OpCanonicalAddress = "canonical_address"
+)
+
+// Call is a single contract call that can be recorded and replayed
+type Call struct {
+	// EntryPoint is one of "init", "handle", "migrate" or "query"
+	EntryPoint string `json:"entry_point"`
+	CodeID     []byte `json:"code_id"`
+	// Params is the json encoded env, unused for queries
+	Params   []byte `json:"params,omitempty"`
+	Msg      []byte `json:"msg"`
+	GasLimit uint64 `json:"gas_limit"`
+}
+
+// HostCall is one callback of a contract into the host, with its inputs and outputs
+type HostCall struct {
+	Op string `json:"op"`
+	// Key is the key of a store operation or next, and the start of an iterator
+	Key []byte `json:"key,omitempty"`
+	// Value is the value read or written by a store operation or next, and the end of an iterator
+	Value []byte `json:"value,omitempty"`
+	// Iterator is the iterator advanced by next, counting the iterators of the call from 1
+	Iterator int `json:"iterator,omitempty"`
+	// Request and Result of a query
+	Request *types.QueryRequest  `json:"request,omitempty"`
+	Result  *types.QuerierResult `json:"result,omitempty"`
+	// Human and Canonical are the addresses converted by the api
+	Human     string `json:"human,omitempty"`
+	Canonical []byte `json:"canonical,omitempty"`
+	// Err is the error returned by the api
+	Err string `json:"error,omitempty"`
+	// Gas is the gas consumed by the gas meter (store operations) or querier (queries) after the call,
+	// or the gas returned by the api
+	Gas uint64 `json:"gas"`
+}
+
+// ReplayLog records every host call of one contract call, together with its outcome,
+// so the call can be re-run without the live store, querier and api (see Replay).
+type ReplayLog struct {
+	Call      Call       `json:"call"`
+	HostCalls []HostCall `json:"host_calls"`
+	// gas consumed by the gas meter and the querier before the call
+	StoreGas uint64 `json:"store_gas"`
+	QueryGas uint64 `json:"query_gas"`
+	// the outcome of the call
+	Result  []byte `json:"result"`
+	GasUsed uint64 `json:"gas_used"`
+	Err     string `json:"error,omitempty"`
+}
+
+// WriteFile dumps the log as json to path
+func (l *ReplayLog) WriteFile(path string) error {
+	bz, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bz, 0o644)
+}
+
+// ReadReplayLog reads a log written by WriteFile
+func ReadReplayLog(path string) (*ReplayLog, error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var log ReplayLog
+	if err := json.Unmarshal(bz, &log); err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// ReplayMismatchError is returned by Replay when the contract did not behave as recorded
+type ReplayMismatchError struct {
+	// HostCall is the index of the first host call that differed, or -1 if the outcome differed
+	HostCall int
+	Msg      string
+}
+
+var _ error = ReplayMismatchError{}
+
+func (e ReplayMismatchError) Error() string {
+	if e.HostCall < 0 {
+		return fmt.Sprintf("replay mismatch: %s", e.Msg)
+	}
+	return fmt.Sprintf("replay mismatch at host call %d: %s", e.HostCall, e.Msg)
+}
+
+// runCall calls the contract, replaced in tests
+var runCall = func(cache Cache, call Call, gasMeter *GasMeter, store KVStore, api *GoAPI, querier *Querier) ([]byte, uint64, error) {
+	switch call.EntryPoint {
+	case "init":
+		return Instantiate(cache, call.CodeID, call.Params, call.Msg, gasMeter, store, api, querier, call.GasLimit)
+	case "handle":
+		return Handle(cache, call.CodeID, call.Params, call.Msg, gasMeter, store, api, querier, call.GasLimit)
+	case "migrate":
+		return Migrate(cache, call.CodeID, call.Params, call.Msg, gasMeter, store, api, querier, call.GasLimit)
+	case "query":
+		return Query(cache, call.CodeID, call.Msg, gasMeter, store, api, querier, call.GasLimit)
+	default:
+		return nil, 0, fmt.Errorf("unknown entry point %q", call.EntryPoint)
+	}
+}
+
+// Record makes the call like Instantiate, Handle, Migrate or Query would, and returns a log of
+// all host calls the contract made along with the result.
+func Record(cache Cache, call Call, gasMeter *GasMeter, store KVStore, api *GoAPI, querier *Querier) ([]byte, uint64, *ReplayLog, error) {
+	rec := &recorder{
+		log:      &ReplayLog{Call: call, StoreGas: (*gasMeter).GasConsumed(), QueryGas: (*querier).GasConsumed()},
+		gasMeter: *gasMeter,
+	}
+	recAPI := &GoAPI{
+		HumanAddress:        rec.humanAddress(api.HumanAddress),
+		CanonicalAddress:    rec.canonicalAddress(api.CanonicalAddress),
+		GetContractCodeHash: api.GetContractCodeHash,
+	}
+	var recQuerier Querier = querierRecorder{rec: rec, querier: *querier}
+	res, gasUsed, err := runCall(cache, call, gasMeter, storeRecorder{rec: rec, store: store}, recAPI, &recQuerier)
+	rec.log.Result = res
+	rec.log.GasUsed = gasUsed
+	if err != nil {
+		rec.log.Err = err.Error()
+	}
+	return res, gasUsed, rec.log, err
+}
+
+// Replay re-runs a recorded call, answering all host calls from the log instead of a live store,
+// querier and api. It returns a ReplayMismatchError if the contract made different host calls or
+// had a different outcome than recorded, i.e. if it did not execute deterministically.
+func Replay(cache Cache, log *ReplayLog) error {
+	rep := &replayer{log: log, storeGas: log.StoreGas, queryGas: log.QueryGas}
+	var gasMeter GasMeter = replayGasMeter{rep}
+	api := &GoAPI{
+		HumanAddress:     rep.humanAddress,
+		CanonicalAddress: rep.canonicalAddress,
+	}
+	var querier Querier = replayQuerier{rep}
+	res, gasUsed, err := runCall(cache, log.Call, &gasMeter, replayStore{rep}, api, &querier)
+	if rep.mismatch != nil {
+		return *rep.mismatch
+	}
+	if rep.next < len(log.HostCalls) {
+		return ReplayMismatchError{HostCall: rep.next, Msg: fmt.Sprintf("contract made %d host calls, recorded %d", rep.next, len(log.HostCalls))}
+	}
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	if errMsg != log.Err {
+		return ReplayMismatchError{HostCall: -1, Msg: fmt.Sprintf("got error %q, recorded %q", errMsg, log.Err)}
+	}
+	if !bytes.Equal(res, log.Result) {
+		return ReplayMismatchError{HostCall: -1, Msg: fmt.Sprintf("got result %s, recorded %s", res, log.Result)}
+	}
+	if gasUsed != log.GasUsed {
+		return ReplayMismatchError{HostCall: -1, Msg: fmt.Sprintf("used %d gas, recorded %d", gasUsed, log.GasUsed)}
+	}
+	return nil
+}
+
+/**** recording ****/
+
+type recorder struct {
+	log       *ReplayLog
+	gasMeter  GasMeter
+	iterators int
+}
+
+func (r *recorder) add(call HostCall) {
+	r.log.HostCalls = append(r.log.HostCalls, call)
+}
+
+func (r *recorder) addStore(call HostCall) {
+	call.Gas = r.gasMeter.GasConsumed()
+	r.add(call)
+}
+
+func (r *recorder) humanAddress(inner HumanizeAddress) HumanizeAddress {
+	return func(canon []byte) (string, uint64, error) {
+		human, gas, err := inner(canon)
+		call := HostCall{Op: OpHumanAddress, Canonical: canon, Human: human, Gas: gas}
+		if err != nil {
+			call.Err = err.Error()
+		}
+		r.add(call)
+		return human, gas, err
+	}
+}
+
+func (r *recorder) canonicalAddress(inner CanonicalizeAddress) CanonicalizeAddress {
+	return func(human string) ([]byte, uint64, error) {
+		canon, gas, err := inner(human)
+		call := HostCall{Op: OpCanonicalAddress, Human: human, Canonical: canon, Gas: gas}
+		if err != nil {
+			call.Err = err.Error()
+		}
+		r.add(call)
+		return canon, gas, err
+	}
+}
+
+type storeRecorder struct {
+	rec   *recorder
+	store KVStore
+}
+
+var _ KVStore = storeRecorder{}
+
+func (s storeRecorder) Get(key []byte) []byte {
+	value := s.store.Get(key)
+	s.rec.addStore(HostCall{Op: OpGet, Key: key, Value: value})
+	return value
+}
+
+func (s storeRecorder) Set(key, value []byte) {
+	s.store.Set(key, value)
+	s.rec.addStore(HostCall{Op: OpSet, Key: key, Value: value})
+}
+
+func (s storeRecorder) Delete(key []byte) {
+	s.store.Delete(key)
+	s.rec.addStore(HostCall{Op: OpDelete, Key: key})
+}
+
+func (s storeRecorder) Iterator(start, end []byte) dbm.Iterator {
+	return s.iterator(OpIterator, start, end, s.store.Iterator(start, end))
+}
+
+func (s storeRecorder) ReverseIterator(start, end []byte) dbm.Iterator {
+	return s.iterator(OpReverseIterator, start, end, s.store.ReverseIterator(start, end))
+}
+
+func (s storeRecorder) iterator(op string, start, end []byte, it dbm.Iterator) dbm.Iterator {
+	s.rec.iterators++
+	s.rec.addStore(HostCall{Op: op, Key: start, Value: end})
+	return &iteratorRecorder{Iterator: it, rec: s.rec, id: s.rec.iterators}
+}
+
+// iteratorRecorder records the entries the contract read.
+// The key and value are kept, as reading them may cost gas.
+type iteratorRecorder struct {
+	dbm.Iterator
+	rec   *recorder
+	id    int
+	key   []byte
+	value []byte
+}
+
+func (it *iteratorRecorder) Key() []byte {
+	it.key = it.Iterator.Key()
+	return it.key
+}
+
+func (it *iteratorRecorder) Value() []byte {
+	it.value = it.Iterator.Value()
+	return it.value
+}
+
+func (it *iteratorRecorder) Next() {
+	it.Iterator.Next()
+	it.rec.addStore(HostCall{Op: OpNext, Iterator: it.id, Key: it.key, Value: it.value})
+	it.key, it.value = nil, nil
+}
+
+type querierRecorder struct {
+	rec     *recorder
+	querier Querier
+}
+
+func (q querierRecorder) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	res, err := q.querier.Query(request, gasLimit)
+	result := types.ToQuerierResult(res, err)
+	q.rec.add(HostCall{Op: OpQuery, Request: &request, Result: &result, Gas: q.querier.GasConsumed()})
+	return res, err
+}
+
+func (q querierRecorder) GasConsumed() uint64 {
+	return q.querier.GasConsumed()
+}
+
+/**** replaying ****/
+
+type replayer struct {
+	log      *ReplayLog
+	next     int
+	storeGas uint64
+	queryGas uint64
+	mismatch *ReplayMismatchError
+}
+
+// peek returns the next recorded host call, if it is of the given op
+func (r *replayer) peek(op string) (HostCall, bool) {
+	if r.next >= len(r.log.HostCalls) || r.log.HostCalls[r.next].Op != op {
+		return HostCall{}, false
+	}
+	return r.log.HostCalls[r.next], true
+}
+
+// take returns the next recorded host call, which must match want in op and inputs.
+// A mismatch aborts the contract call, it is reported by Replay.
+func (r *replayer) take(want HostCall, matches func(got HostCall) bool) HostCall {
+	call, ok := r.peek(want.Op)
+	if !ok || !matches(call) {
+		recorded := "no more host calls"
+		if r.next < len(r.log.HostCalls) {
+			recorded = describe(r.log.HostCalls[r.next])
+		}
+		r.fail(fmt.Sprintf("contract made %s, recorded %s", describe(want), recorded))
+	}
+	r.next++
+	return call
+}
+
+// describe returns a short description of a host call and its inputs for errors
+func describe(call HostCall) string {
+	switch call.Op {
+	case OpGet, OpDelete:
+		return fmt.Sprintf("%s %q", call.Op, call.Key)
+	case OpSet:
+		return fmt.Sprintf("%s %q = %q", call.Op, call.Key, call.Value)
+	case OpIterator, OpReverseIterator:
+		return fmt.Sprintf("%s [%q, %q)", call.Op, call.Key, call.Value)
+	case OpNext:
+		return fmt.Sprintf("%s on iterator %d", call.Op, call.Iterator)
+	case OpQuery:
+		request, _ := json.Marshal(call.Request)
+		return fmt.Sprintf("%s %s", call.Op, request)
+	case OpHumanAddress:
+		return fmt.Sprintf("%s %X", call.Op, call.Canonical)
+	default:
+		return fmt.Sprintf("%s %q", call.Op, call.Human)
+	}
+}
+
+func (r *replayer) fail(msg string) {
+	if r.mismatch == nil {
+		r.mismatch = &ReplayMismatchError{HostCall: r.next, Msg: msg}
+	}
+	panic(*r.mismatch)
+}
+
+func (r *replayer) takeStore(want HostCall) HostCall {
+	call := r.take(want, func(got HostCall) bool {
+		return bytes.Equal(got.Key, want.Key) && (want.Op == OpGet || bytes.Equal(got.Value, want.Value))
+	})
+	r.storeGas = call.Gas
+	return call
+}
+
+func (r *replayer) humanAddress(canon []byte) (string, uint64, error) {
+	call := r.take(HostCall{Op: OpHumanAddress, Canonical: canon}, func(got HostCall) bool {
+		return bytes.Equal(got.Canonical, canon)
+	})
+	return call.Human, call.Gas, apiError(call.Err)
+}
+
+func (r *replayer) canonicalAddress(human string) ([]byte, uint64, error) {
+	call := r.take(HostCall{Op: OpCanonicalAddress, Human: human}, func(got HostCall) bool {
+		return got.Human == human
+	})
+	return call.Canonical, call.Gas, apiError(call.Err)
+}
+
+func apiError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return errors.New(msg)
+}
+
+type replayGasMeter struct {
+	rep *replayer
+}
+
+func (m replayGasMeter) GasConsumed() Gas {
+	return m.rep.storeGas
+}
+
+type replayStore struct {
+	rep *replayer
+}
+
+var _ KVStore = replayStore{}
+
+func (s replayStore) Get(key []byte) []byte {
+	return s.rep.takeStore(HostCall{Op: OpGet, Key: key}).Value
+}
+
+func (s replayStore) Set(key, value []byte) {
+	s.rep.takeStore(HostCall{Op: OpSet, Key: key, Value: value})
+}
+
+func (s replayStore) Delete(key []byte) {
+	s.rep.takeStore(HostCall{Op: OpDelete, Key: key})
+}
+
+func (s replayStore) Iterator(start, end []byte) dbm.Iterator {
+	return s.iterator(OpIterator, start, end)
+}
+
+func (s replayStore) ReverseIterator(start, end []byte) dbm.Iterator {
+	return s.iterator(OpReverseIterator, start, end)
+}
+
+func (s replayStore) iterator(op string, start, end []byte) dbm.Iterator {
+	s.rep.takeStore(HostCall{Op: op, Key: start, Value: end})
+	ids := 0
+	for _, call := range s.rep.log.HostCalls[:s.rep.next] {
+		if call.Op == OpIterator || call.Op == OpReverseIterator {
+			ids++
+		}
+	}
+	return &replayIterator{rep: s.rep, id: ids, start: start, end: end}
+}
+
+// replayIterator is valid as long as the next recorded host call advances it
+type replayIterator struct {
+	rep        *replayer
+	id         int
+	start, end []byte
+}
+
+var _ dbm.Iterator = (*replayIterator)(nil)
+
+func (it *replayIterator) current() (HostCall, bool) {
+	call, ok := it.rep.peek(OpNext)
+	return call, ok && call.Iterator == it.id
+}
+
+func (it *replayIterator) Domain() ([]byte, []byte) {
+	return it.start, it.end
+}
+
+func (it *replayIterator) Valid() bool {
+	_, ok := it.current()
+	return ok
+}
+
+func (it *replayIterator) Key() []byte {
+	call, _ := it.current()
+	return call.Key
+}
+
+func (it *replayIterator) Value() []byte {
+	call, _ := it.current()
+	return call.Value
+}
+
+func (it *replayIterator) Next() {
+	if _, ok := it.current(); !ok {
+		it.rep.fail(fmt.Sprintf("contract advanced iterator %d past its recorded end", it.id))
+	}
+	it.rep.storeGas = it.rep.log.HostCalls[it.rep.next].Gas
+	it.rep.next++
+}
+
+func (it *replayIterator) Error() error {
+	return nil
+}
+
+func (it *replayIterator) Close() error {
+	return nil
+}
+
+type replayQuerier struct {
+	rep *replayer
+}
+
+func (q replayQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	want, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	call := q.rep.take(HostCall{Op: OpQuery, Request: &request}, func(got HostCall) bool {
+		recorded, err := json.Marshal(got.Request)
+		return err == nil && bytes.Equal(recorded, want)
+	})
+	q.rep.queryGas = call.Gas
+	switch {
+	case call.Result == nil:
+		return nil, types.Unknown{}
+	case call.Result.Err != nil:
+		return nil, *call.Result.Err
+	case call.Result.Ok.Err != nil:
+		return nil, *call.Result.Ok.Err
+	default:
+		return call.Result.Ok.Ok, nil
+	}
+}
+
+func (q replayQuerier) GasConsumed() uint64 {
+	return q.rep.queryGas
+}
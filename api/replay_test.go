@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// counterContract stands in for a contract, making the same kinds of host calls.
+// Like the real callbacks, it turns panics of the host into errors.
+func counterContract(countKey string) func(Cache, Call, *GasMeter, KVStore, *GoAPI, *Querier) ([]byte, uint64, error) {
+	return func(cache Cache, call Call, gasMeter *GasMeter, store KVStore, api *GoAPI, querier *Querier) (res []byte, gasUsed uint64, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("panic in host call: %v", rec)
+			}
+		}()
+		canon, _, err := api.CanonicalAddress(string(call.Msg))
+		if err != nil {
+			return nil, 0, err
+		}
+		human, _, err := api.HumanAddress(canon)
+		if err != nil {
+			return nil, 0, err
+		}
+		count, _ := strconv.Atoi(string(store.Get([]byte(countKey))))
+		entries := 0
+		it := store.Iterator(nil, nil)
+		for ; it.Valid(); it.Next() {
+			_, _ = it.Key(), it.Value()
+			entries++
+		}
+		it.Close()
+		balances, err := (*querier).Query(types.QueryRequest{Bank: &types.BankQuery{AllBalances: &types.AllBalancesQuery{Address: human}}}, 1000)
+		if err != nil {
+			return nil, 0, err
+		}
+		store.Set([]byte(countKey), []byte(strconv.Itoa(count+1)))
+		res, err = json.Marshal(map[string]interface{}{"count": count, "entries": entries, "balances": balances})
+		return res, (*gasMeter).GasConsumed() + (*querier).GasConsumed(), err
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	defer func(run func(Cache, Call, *GasMeter, KVStore, *GoAPI, *Querier) ([]byte, uint64, error)) {
+		runCall = run
+	}(runCall)
+	runCall = counterContract("count")
+
+	gasMeter := NewMockGasMeter(100000000)
+	igasMeter := GasMeter(gasMeter)
+	store := NewLookup(gasMeter)
+	store.Set([]byte("count"), []byte("41"))
+	store.Set([]byte("other"), []byte("value"))
+	api := NewMockAPI()
+	querier := DefaultQuerier("alice", types.Coins{types.NewCoin(100, "ATOM")})
+
+	call := Call{EntryPoint: "handle", CodeID: []byte("code"), Msg: []byte("alice"), GasLimit: 100000000}
+	res, gasUsed, log, err := Record(Cache{}, call, &igasMeter, store, api, &querier)
+	require.NoError(t, err)
+	assert.Contains(t, string(res), `"count":41,"entries":2`)
+	assert.Equal(t, []byte("42"), store.Get([]byte("count")))
+	assert.Equal(t, res, log.Result)
+	assert.Equal(t, gasUsed, log.GasUsed)
+
+	var ops []string
+	for _, call := range log.HostCalls {
+		ops = append(ops, call.Op)
+	}
+	assert.Equal(t, []string{OpCanonicalAddress, OpHumanAddress, OpGet, OpIterator, OpNext, OpNext, OpQuery, OpSet}, ops)
+
+	// the log survives a round trip through a file, and replays without the store
+	dir, err := ioutil.TempDir("", "replay")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.json")
+	require.NoError(t, log.WriteFile(path))
+	loaded, err := ReadReplayLog(path)
+	require.NoError(t, err)
+	require.NoError(t, Replay(Cache{}, loaded))
+
+	// a contract making other host calls is caught where it diverges
+	runCall = counterContract("counter")
+	err = Replay(Cache{}, loaded)
+	require.Error(t, err)
+	mismatch, ok := err.(ReplayMismatchError)
+	require.True(t, ok)
+	assert.Equal(t, 2, mismatch.HostCall)
+	assert.EqualError(t, err, `replay mismatch at host call 2: contract made get "counter", recorded get "count"`)
+
+	// as are different responses from the host
+	runCall = counterContract("count")
+	loaded.HostCalls[2].Value = []byte("7")
+	err = Replay(Cache{}, loaded)
+	require.EqualError(t, err, `replay mismatch at host call 7: contract made set "count" = "8", recorded set "count" = "42"`)
+	loaded.HostCalls[2].Value = []byte("41")
+	loaded.HostCalls[6].Result.Ok.Ok = []byte(`{"amount":[]}`)
+	err = Replay(Cache{}, loaded)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "replay mismatch: got result")
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"fmt"
+)
+
+// QueryRaw reads the value stored under key directly from a contract's store,
+// without executing any contract code. It returns nil if the key is missing.
+// No code is loaded, so there is no code id to pass: the store alone identifies the contract.
+//
+// If a gasMeter is provided, the gas consumed by the read is reported just like
+// the db callbacks do. Note that the contract state is encrypted by the enclave,
+// so the returned value is the raw (encrypted) value as it is stored.
+func QueryRaw(store KVStore, key []byte, gasMeter *GasMeter) ([]byte, uint64, error) {
+	if store == nil {
+		return nil, 0, fmt.Errorf("Null/Empty argument: store")
+	}
+
+	var gm GasMeter
+	if gasMeter != nil {
+		gm = *gasMeter
+	}
+	if gm == nil {
+		return store.Get(key), 0, nil
+	}
+
+	gasBefore := gm.GasConsumed()
+	v := store.Get(key)
+	gasAfter := gm.GasConsumed()
+	return v, gasAfter - gasBefore, nil
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRaw(t *testing.T) {
+	gasMeter1 := NewMockGasMeter(100000000)
+	store := NewLookup(gasMeter1)
+	store.Set([]byte("foo"), []byte("bar"))
+
+	// present key, metered
+	gasMeter2 := NewMockGasMeter(100000000)
+	igasMeter2 := GasMeter(gasMeter2)
+	store.SetGasMeter(gasMeter2)
+	val, cost, err := QueryRaw(store, []byte("foo"), &igasMeter2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), val)
+	assert.Equal(t, GetPrice, cost)
+
+	// absent key, metered
+	val, cost, err = QueryRaw(store, []byte("missing"), &igasMeter2)
+	require.NoError(t, err)
+	assert.Nil(t, val)
+	assert.Equal(t, GetPrice, cost)
+
+	// no gas meter supplied
+	val, cost, err = QueryRaw(store, []byte("foo"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), val)
+	assert.Equal(t, uint64(0), cost)
+}
+
+func TestQueryRawFailsWithBadArgs(t *testing.T) {
+	_, _, err := QueryRaw(nil, []byte("foo"), nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package cosmwasm
+
+import (
+	"context"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// cancelledCall is the panic raised in a callback once the context of the vm call is done.
+// The vm turns a panicking callback into an error and aborts the contract, so this stops
+// the execution at the next point it talks to the host (storage access or query).
+// The name is matched in api.recoverPanic.
+type cancelledCall struct {
+	err error
+}
+
+// cancellation aborts a vm call when its context is done
+type cancellation struct {
+	ctx     context.Context
+	tripped bool
+}
+
+// check panics with a cancelledCall if the context is done
+func (c *cancellation) check() {
+	if err := c.ctx.Err(); err != nil {
+		c.tripped = true
+		panic(cancelledCall{err: err})
+	}
+}
+
+// wrap returns a CancelledError in place of err if the call was aborted by check
+func (c *cancellation) wrap(err error) error {
+	if err == nil || c == nil || !c.tripped {
+		return err
+	}
+	return types.CancelledError{Err: c.ctx.Err()}
+}
+
+// cancelMeter checks for cancellation whenever the vm consults the gas meter,
+// which it does on every storage access
+type cancelMeter struct {
+	GasMeter
+	cancel *cancellation
+}
+
+func (m cancelMeter) GasConsumed() uint64 {
+	m.cancel.check()
+	return m.GasMeter.GasConsumed()
+}
+
+// cancelQuerier checks for cancellation before every query
+type cancelQuerier struct {
+	Querier
+	cancel *cancellation
+}
+
+func (q cancelQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	q.cancel.check()
+	return q.Querier.Query(request, gasLimit)
+}
+
+// withCancellation makes the vm call abort once ctx is done.
+// Contexts that can never be done (e.g. context.Background) leave everything unchanged.
+// A context that is done already fails the call before it reaches the vm.
+func withCancellation(ctx context.Context, gasMeter GasMeter, querier Querier) (GasMeter, Querier, *cancellation, error) {
+	if ctx == nil || ctx.Done() == nil {
+		return gasMeter, querier, nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, types.CancelledError{Err: err}
+	}
+	cancel := &cancellation{ctx: ctx}
+	if gasMeter != nil {
+		gasMeter = cancelMeter{GasMeter: gasMeter, cancel: cancel}
+	}
+	if querier != nil {
+		querier = cancelQuerier{Querier: querier, cancel: cancel}
+	}
+	return gasMeter, querier, cancel, nil
+}
@@ -0,0 +1,95 @@
+package cosmwasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+type countingMeter struct {
+	consumed uint64
+}
+
+func (m *countingMeter) GasConsumed() uint64 {
+	return m.consumed
+}
+
+// runSteps mimics a contract doing one storage access per step, and the vm aborting it
+// when a callback panics (see api.recoverPanic)
+func runSteps(gasMeter GasMeter, steps int, onStep func(int)) (done int, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic in go callback")
+		}
+	}()
+	for done = 0; done < steps; done++ {
+		onStep(done)
+		gasMeter.GasConsumed()
+	}
+	return done, nil
+}
+
+func TestCancelMidExecution(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	gasMeter, querier, cancel, err := withCancellation(ctx, &countingMeter{}, plainQuerier{})
+	require.NoError(t, err)
+	require.NotNil(t, cancel)
+
+	done, err := runSteps(gasMeter, 10, func(step int) {
+		if step == 3 {
+			cancelCtx()
+		}
+	})
+	assert.Equal(t, 3, done)
+	err = cancel.wrap(err)
+	assert.Equal(t, types.CancelledError{Err: context.Canceled}, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// queries are refused as well
+	assert.PanicsWithValue(t, cancelledCall{err: context.Canceled}, func() {
+		_, _ = querier.Query(smartQuery("ok"), 1000)
+	})
+}
+
+func TestCancelNotTripped(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+
+	gasMeter, _, cancel, err := withCancellation(ctx, &countingMeter{}, nil)
+	require.NoError(t, err)
+	done, err := runSteps(gasMeter, 10, func(int) {})
+	require.NoError(t, err)
+	assert.Equal(t, 10, done)
+
+	// other errors of the call are kept, even if the context is done by now
+	cancelCtx()
+	callErr := fmt.Errorf("contract failed")
+	assert.Equal(t, callErr, cancel.wrap(callErr))
+}
+
+func TestCancelBackgroundUnchanged(t *testing.T) {
+	meter := &countingMeter{}
+	gasMeter, querier, cancel, err := withCancellation(context.Background(), meter, plainQuerier{})
+	require.NoError(t, err)
+	assert.Nil(t, cancel)
+	assert.Equal(t, meter, gasMeter)
+	assert.Equal(t, plainQuerier{}, querier)
+	assert.NoError(t, cancel.wrap(nil))
+}
+
+func TestCancelledBeforeCall(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	cancelCtx()
+
+	w := Wasmer{}
+	_, _, err := w.QueryContext(ctx, codeA, []byte(`{}`), nil, GoAPI{}, plainQuerier{}, &countingMeter{}, 1000)
+	assert.Equal(t, types.CancelledError{Err: context.Canceled}, err)
+}
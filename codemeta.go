@@ -0,0 +1,73 @@
+package cosmwasm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// codeMetaDir is the subdirectory of the data dir holding the metadata of stored code
+const codeMetaDir = "meta"
+
+// StoreCodeWithMeta works like StoreCode, and also persists meta (e.g. the source URL or
+// the builder of the code) in the data dir, where GetCodeMeta can read it after a restart.
+// Storing metadata for a checksum replaces the metadata stored for it before.
+// Empty metadata leaves the stored metadata untouched.
+func (w *Wasmer) StoreCodeWithMeta(code WasmCode, meta map[string]string) (*StoreResult, error) {
+	res, err := w.StoreCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if len(meta) > 0 {
+		if err := w.saveCodeMeta(res.Checksum, meta); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// GetCodeMeta returns the metadata stored with the code, see StoreCodeWithMeta.
+// Code stored without metadata has an empty map.
+func (w *Wasmer) GetCodeMeta(checksum CodeID) (map[string]string, error) {
+	bz, err := ioutil.ReadFile(w.codeMetaPath(checksum))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading code metadata: %w", err)
+	}
+	meta := map[string]string{}
+	if err := json.Unmarshal(bz, &meta); err != nil {
+		return nil, fmt.Errorf("invalid code metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// saveCodeMeta writes the metadata of checksum. The file is written next to its final name
+// and renamed, so a crash never leaves half written metadata behind.
+func (w *Wasmer) saveCodeMeta(checksum CodeID, meta map[string]string) error {
+	bz, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	path := w.codeMetaPath(checksum)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("writing code metadata: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bz, 0o644); err != nil {
+		return fmt.Errorf("writing code metadata: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("writing code metadata: %w", err)
+	}
+	return nil
+}
+
+// codeMetaPath is the file holding the metadata of checksum
+func (w *Wasmer) codeMetaPath(checksum CodeID) string {
+	return filepath.Join(w.dataDir, codeMetaDir, hex.EncodeToString(checksum)+".json")
+}
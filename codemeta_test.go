@@ -0,0 +1,68 @@
+package cosmwasm
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeMetaStoreAndRead(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "go-cosmwasm")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	w := Wasmer{dataDir: tmpdir}
+	meta := map[string]string{"source": "https://example.com/hackatom", "builder": "cosmwasm/rust-optimizer:0.10.4"}
+	require.NoError(t, w.saveCodeMeta(codeA, meta))
+	loaded, err := w.GetCodeMeta(codeA)
+	require.NoError(t, err)
+	assert.Equal(t, meta, loaded)
+
+	// a new Wasmer on the same dir, as after a restart, still finds it
+	restarted := Wasmer{dataDir: tmpdir}
+	loaded, err = restarted.GetCodeMeta(codeA)
+	require.NoError(t, err)
+	assert.Equal(t, meta, loaded)
+
+	// storing again replaces the metadata
+	require.NoError(t, w.saveCodeMeta(codeA, map[string]string{"source": "ipfs://hackatom"}))
+	loaded, err = w.GetCodeMeta(codeA)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"source": "ipfs://hackatom"}, loaded)
+}
+
+func TestCodeMetaMissing(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "go-cosmwasm")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	w := Wasmer{dataDir: tmpdir}
+	require.NoError(t, w.saveCodeMeta(codeA, map[string]string{"source": "ipfs://hackatom"}))
+	meta, err := w.GetCodeMeta(codeB)
+	require.NoError(t, err)
+	assert.NotNil(t, meta)
+	assert.Empty(t, meta)
+}
+
+func TestStoreCodeWithMeta(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "go-cosmwasm")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	w, err := NewWasmer(tmpdir, "staking", 3)
+	require.NoError(t, err)
+
+	meta := map[string]string{"source": "https://example.com/hackatom"}
+	res, err := w.StoreCodeWithMeta(readTestdata(t, "hackatom.wasm"), meta)
+	require.NoError(t, err)
+	w.Cleanup()
+
+	w, err = NewWasmer(tmpdir, "staking", 3)
+	require.NoError(t, err)
+	defer w.Cleanup()
+	loaded, err := w.GetCodeMeta(res.Checksum)
+	require.NoError(t, err)
+	assert.Equal(t, meta, loaded)
+}
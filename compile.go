@@ -0,0 +1,42 @@
+package cosmwasm
+
+import (
+	"time"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/api"
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// compileFunc compiles and stores code in the cache, like api.Create
+type compileFunc func(cache api.Cache, wasm []byte) ([]byte, error)
+
+// compile runs the compiler, giving up after the compile timeout if one is set.
+// The enclave cannot be interrupted, so a timed out compilation keeps running in the
+// background and its result is dropped.
+func (w *Wasmer) compile(code WasmCode) (CodeID, error) {
+	compile := w.compiler
+	if compile == nil {
+		compile = api.Create
+	}
+	if w.compileTimeout == 0 {
+		return compile(w.cache, code)
+	}
+
+	type result struct {
+		id  CodeID
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		id, err := compile(w.cache, code)
+		done <- result{id, err}
+	}()
+	timer := time.NewTimer(w.compileTimeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.id, res.err
+	case <-timer.C:
+		return nil, types.CompileTimeoutError{Timeout: w.compileTimeout}
+	}
+}
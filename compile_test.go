@@ -0,0 +1,43 @@
+package cosmwasm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/api"
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// slowCompiler compiles anything to codeA after delay
+func slowCompiler(delay time.Duration) compileFunc {
+	return func(cache api.Cache, wasm []byte) ([]byte, error) {
+		time.Sleep(delay)
+		return codeA, nil
+	}
+}
+
+func TestCompileTimeout(t *testing.T) {
+	w := Wasmer{compiler: slowCompiler(time.Second)}
+	CompileTimeout(10 * time.Millisecond)(&w)
+	start := time.Now()
+	_, err := w.Create([]byte("\x00asm"))
+	require.Equal(t, types.CompileTimeoutError{Timeout: 10 * time.Millisecond}, err)
+	assert.EqualError(t, err, "compiling code took longer than 10ms")
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// fast enough
+	w.compiler = slowCompiler(time.Millisecond)
+	id, err := w.Create([]byte("\x00asm"))
+	require.NoError(t, err)
+	assert.Equal(t, codeA, id)
+}
+
+func TestCompileTimeoutOffByDefault(t *testing.T) {
+	w := Wasmer{compiler: slowCompiler(50 * time.Millisecond)}
+	id, err := w.Create([]byte("\x00asm"))
+	require.NoError(t, err)
+	assert.Equal(t, codeA, id)
+}
@@ -0,0 +1,60 @@
+package cosmwasm
+
+import (
+	"context"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// ContextQuerier is a Querier which also wants to know the context of the VM call it serves
+// (e.g. to get hold of the sdk.Context). If the querier passed to one of the *Context methods
+// of Wasmer implements it, all queries from the contract go through QueryContext instead of Query.
+type ContextQuerier interface {
+	Querier
+	QueryContext(ctx context.Context, request types.QueryRequest, gasLimit uint64) ([]byte, error)
+}
+
+// ContextKVStore is a KVStore which also wants to know the context of the VM call it serves.
+// If the store passed to one of the *Context methods of Wasmer implements it, the contract
+// storage callbacks of that call go to the store returned by WithContext.
+type ContextKVStore interface {
+	KVStore
+	WithContext(ctx context.Context) KVStore
+}
+
+// contextQuerier binds a ContextQuerier to the context of one VM call
+type contextQuerier struct {
+	ctx context.Context
+	ContextQuerier
+}
+
+func (q contextQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	return q.QueryContext(q.ctx, request, gasLimit)
+}
+
+// withContext threads ctx into the querier callbacks, if the querier supports it.
+// Plain queriers are returned unchanged.
+func withContext(ctx context.Context, querier Querier) Querier {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cq, ok := querier.(ContextQuerier); ok {
+		return contextQuerier{ctx: ctx, ContextQuerier: cq}
+	}
+	return querier
+}
+
+// bindContext threads ctx into the store and GoAPI callbacks of a call, if they support it
+// (see ContextKVStore and GoAPI.BindContext)
+func bindContext(ctx context.Context, store KVStore, goapi GoAPI) (KVStore, GoAPI) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if cs, ok := store.(ContextKVStore); ok {
+		store = cs.WithContext(ctx)
+	}
+	if goapi.BindContext != nil {
+		goapi = goapi.BindContext(ctx)
+	}
+	return store, goapi
+}
@@ -0,0 +1,105 @@
+package cosmwasm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+type ctxKey struct{}
+
+// recordingQuerier remembers the context of the last query
+type recordingQuerier struct {
+	seen context.Context
+}
+
+var _ ContextQuerier = (*recordingQuerier)(nil)
+
+func (q *recordingQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	return q.QueryContext(nil, request, gasLimit)
+}
+
+func (q *recordingQuerier) QueryContext(ctx context.Context, request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	q.seen = ctx
+	return []byte(`{}`), nil
+}
+
+func (q *recordingQuerier) GasConsumed() uint64 {
+	return 0
+}
+
+type plainQuerier struct{}
+
+func (plainQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	return []byte(`{}`), nil
+}
+
+func (plainQuerier) GasConsumed() uint64 {
+	return 0
+}
+
+func TestWithContextReachesQuerier(t *testing.T) {
+	req, err := json.Marshal(types.QueryRequest{Bank: &types.BankQuery{AllBalances: &types.AllBalancesQuery{Address: "foo"}}})
+	require.NoError(t, err)
+
+	// this is the path the vm uses to call back into the querier
+	q := &recordingQuerier{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "sdk.Context")
+	res := types.RustQuery(withContext(ctx, q), req, 1000)
+	require.NotNil(t, res.Ok)
+	require.NotNil(t, q.seen)
+	assert.Equal(t, "sdk.Context", q.seen.Value(ctxKey{}))
+
+	// without a context, we still get a valid one
+	res = types.RustQuery(withContext(nil, q), req, 1000)
+	require.NotNil(t, res.Ok)
+	assert.Equal(t, context.Background(), q.seen)
+
+	// a plain querier keeps working
+	var plain Querier = plainQuerier{}
+	assert.Equal(t, plain, withContext(ctx, plain))
+}
+
+// contextStore remembers the context it was bound to
+type contextStore struct {
+	KVStore
+	seen context.Context
+}
+
+func (s *contextStore) WithContext(ctx context.Context) KVStore {
+	s.seen = ctx
+	return s
+}
+
+func TestBindContextReachesStoreAndAPI(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "sdk.Context")
+	store := &contextStore{}
+	var apiCtx context.Context
+	goapi := GoAPI{
+		BindContext: func(ctx context.Context) GoAPI {
+			return GoAPI{HumanAddress: func(canon []byte) (string, uint64, error) {
+				apiCtx = ctx
+				return "human", 0, nil
+			}}
+		},
+	}
+
+	boundStore, boundAPI := bindContext(ctx, store, goapi)
+	assert.Equal(t, store, boundStore)
+	assert.Equal(t, "sdk.Context", store.seen.Value(ctxKey{}))
+	_, _, err := boundAPI.HumanAddress([]byte{1})
+	require.NoError(t, err)
+	assert.Equal(t, "sdk.Context", apiCtx.Value(ctxKey{}))
+
+	// plain stores and apis are kept as they are
+	plain := GoAPI{HumanAddress: func(canon []byte) (string, uint64, error) { return "", 0, nil }}
+	plainStore, plainAPI := bindContext(ctx, nil, plain)
+	assert.Nil(t, plainStore)
+	assert.Nil(t, plainAPI.BindContext)
+	assert.NotNil(t, plainAPI.HumanAddress)
+}
@@ -0,0 +1,72 @@
+package cosmwasm
+
+import (
+	"sync"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// DefaultMaxCallDepth is a reasonable limit on nested contract calls, deep enough
+// for any sane contract interaction but far from exhausting the Go stack.
+const DefaultMaxCallDepth = 64
+
+// CallDepth tracks how deeply contract executions are nested, e.g. when a contract
+// dispatches a WasmMsg that executes another contract (or itself), which in turn
+// dispatches more messages.
+//
+// Contract-to-contract messages are dispatched by the caller (the compute keeper),
+// after the calling contract returns, so the caller owns the CallDepth: create one
+// per transaction and wrap every nested execute dispatch in Run.
+type CallDepth struct {
+	mtx     sync.Mutex
+	max     uint32
+	current uint32
+}
+
+// NewCallDepth creates a tracker allowing at most maxDepth nested calls.
+// A maxDepth of 0 means DefaultMaxCallDepth.
+func NewCallDepth(maxDepth uint32) *CallDepth {
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxCallDepth
+	}
+	return &CallDepth{max: maxDepth}
+}
+
+// Enter marks the start of a nested call. It returns a MaxCallDepthError if
+// this call would exceed the maximum depth, in which case Exit must not be called.
+func (d *CallDepth) Enter() error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.current >= d.max {
+		return types.MaxCallDepthError{Max: d.max}
+	}
+	d.current++
+	return nil
+}
+
+// Exit marks the end of a nested call started with a successful Enter
+func (d *CallDepth) Exit() {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.current == 0 {
+		panic("CallDepth.Exit called without a matching Enter")
+	}
+	d.current--
+}
+
+// Current returns the number of calls currently in progress
+func (d *CallDepth) Current() uint32 {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return d.current
+}
+
+// Run executes call one level deeper, making sure the depth is restored
+// when it returns, whether it errored, or not.
+func (d *CallDepth) Run(call func() error) error {
+	if err := d.Enter(); err != nil {
+		return err
+	}
+	defer d.Exit()
+	return call()
+}
@@ -0,0 +1,64 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// callSelf emulates a contract that dispatches an execute to itself n more times
+func callSelf(depth *CallDepth, n int, maxSeen *uint32) error {
+	return depth.Run(func() error {
+		if cur := depth.Current(); cur > *maxSeen {
+			*maxSeen = cur
+		}
+		if n == 0 {
+			return nil
+		}
+		return callSelf(depth, n-1, maxSeen)
+	})
+}
+
+func TestCallDepthSelfCalls(t *testing.T) {
+	depth := NewCallDepth(10)
+
+	// 1 outer call + 9 nested self-calls is exactly at the limit
+	var maxSeen uint32
+	err := callSelf(depth, 9, &maxSeen)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), maxSeen)
+	assert.Equal(t, uint32(0), depth.Current())
+
+	// one more nesting is too deep
+	maxSeen = 0
+	err = callSelf(depth, 10, &maxSeen)
+	require.Error(t, err)
+	assert.Equal(t, types.MaxCallDepthError{Max: 10}, err)
+	assert.Equal(t, "max call depth of 10 exceeded", err.Error())
+	// all levels were unwound
+	assert.Equal(t, uint32(0), depth.Current())
+}
+
+func TestCallDepthUnwindsOnError(t *testing.T) {
+	depth := NewCallDepth(0)
+	assert.Equal(t, uint32(DefaultMaxCallDepth), depth.max)
+
+	err := depth.Run(func() error {
+		return depth.Run(func() error {
+			assert.Equal(t, uint32(2), depth.Current())
+			return fmt.Errorf("nested call failed")
+		})
+	})
+	require.EqualError(t, err, "nested call failed")
+	assert.Equal(t, uint32(0), depth.Current())
+
+	// we can still use the full depth afterwards
+	var maxSeen uint32
+	err = callSelf(depth, DefaultMaxCallDepth-1, &maxSeen)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(DefaultMaxCallDepth), maxSeen)
+}
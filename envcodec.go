@@ -0,0 +1,51 @@
+package cosmwasm
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// CBORInterfaceExport is the function a contract exports to declare it accepts its Env as CBOR
+// (see types.MarshalCBOR) instead of json
+const CBORInterfaceExport = "interface_cbor"
+
+// cborEnv marks the context of a call that asked for a CBOR Env, see WithCBOREnv
+type cborEnv struct{}
+
+// WithCBOREnv returns a context that makes the *Context methods of Wasmer pass the Env as CBOR,
+// a more compact encoding than json, to contracts exporting CBORInterfaceExport.
+// All other contracts keep getting json, which is also the default for every call.
+// Responses are json either way.
+func WithCBOREnv(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, cborEnv{}, true)
+}
+
+// encodeEnv encodes the env for a call of code. CBOR is only used if the call asked for it,
+// which is the only case where the code is loaded to check for CBORInterfaceExport.
+func (w *Wasmer) encodeEnv(ctx context.Context, code CodeID, env types.Env) ([]byte, error) {
+	if ctx == nil || ctx.Value(cborEnv{}) == nil {
+		return json.Marshal(env)
+	}
+	wasm, err := w.GetCode(code)
+	if err != nil {
+		return nil, err
+	}
+	return encodeEnvFor(wasm, env)
+}
+
+// encodeEnvFor encodes env as CBOR if the contract wasm exports CBORInterfaceExport, json otherwise
+func encodeEnvFor(wasm []byte, env types.Env) ([]byte, error) {
+	module, err := parseWasm(wasm)
+	if err != nil {
+		return nil, err
+	}
+	if !module.hasExport(CBORInterfaceExport) {
+		return json.Marshal(env)
+	}
+	return types.MarshalCBOR(env)
+}
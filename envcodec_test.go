@@ -0,0 +1,53 @@
+package cosmwasm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// wasmExporting is a module exporting one function named name
+func wasmExporting(name string) []byte {
+	section := append([]byte{0x01, byte(len(name))}, name...)
+	section = append(section, externFunc, 0x00)
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, sectionExport, byte(len(section))}
+	return append(code, section...)
+}
+
+func TestEncodeEnv(t *testing.T) {
+	env := types.Env{
+		Block:    types.BlockInfo{Height: 123, Time: 1578939743, ChainID: "secret-2"},
+		Message:  types.MessageInfo{Sender: "secret1sender"},
+		Contract: types.ContractInfo{Address: "secret1contract"},
+		Key:      "contract-key",
+	}
+	js, err := json.Marshal(env)
+	require.NoError(t, err)
+	cbor, err := types.MarshalCBOR(env)
+	require.NoError(t, err)
+
+	// contracts supporting it get CBOR
+	bz, err := encodeEnvFor(wasmExporting(CBORInterfaceExport), env)
+	require.NoError(t, err)
+	assert.Equal(t, cbor, bz)
+	var decoded types.Env
+	require.NoError(t, types.UnmarshalCBOR(bz, &decoded))
+	assert.Equal(t, env, decoded)
+
+	// all others keep json
+	bz, err = encodeEnvFor(wasmExporting("handle"), env)
+	require.NoError(t, err)
+	assert.Equal(t, js, bz)
+
+	// and calls not asking for it never load the code
+	w := Wasmer{}
+	bz, err = w.encodeEnv(context.Background(), codeA, env)
+	require.NoError(t, err)
+	assert.Equal(t, js, bz)
+	assert.NotNil(t, WithCBOREnv(context.Background()).Value(cborEnv{}))
+}
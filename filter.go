@@ -0,0 +1,61 @@
+package cosmwasm
+
+import (
+	"sync"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// codeFilter decides which codes may be executed. It is safe for concurrent use,
+// so the lists can be swapped at runtime (e.g. to emergency-halt a contract).
+type codeFilter struct {
+	mtx sync.RWMutex
+	// if not nil, only these checksums may run
+	allow map[string]struct{}
+	// these checksums may never run, even if allowlisted
+	deny map[string]struct{}
+}
+
+func toCodeSet(codes []CodeID) map[string]struct{} {
+	if codes == nil {
+		return nil
+	}
+	set := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		set[string(code)] = struct{}{}
+	}
+	return set
+}
+
+func (f *codeFilter) check(code CodeID) error {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	if _, denied := f.deny[string(code)]; denied {
+		return types.ContractPausedError{CodeID: code}
+	}
+	if f.allow != nil {
+		if _, allowed := f.allow[string(code)]; !allowed {
+			return types.ContractPausedError{CodeID: code}
+		}
+	}
+	return nil
+}
+
+// SetCodeAllowlist restricts execution to the given codes. Every call to any other code
+// fails with a ContractPausedError before the contract is loaded.
+// Passing nil removes the allowlist, so all codes (that are not denylisted) may run.
+func (w *Wasmer) SetCodeAllowlist(codes []CodeID) {
+	set := toCodeSet(codes)
+	w.filter.mtx.Lock()
+	defer w.filter.mtx.Unlock()
+	w.filter.allow = set
+}
+
+// SetCodeDenylist replaces the list of paused codes. Every call to these codes fails
+// with a ContractPausedError before the contract is loaded. Passing nil un-pauses all codes.
+func (w *Wasmer) SetCodeDenylist(codes []CodeID) {
+	set := toCodeSet(codes)
+	w.filter.mtx.Lock()
+	defer w.filter.mtx.Unlock()
+	w.filter.deny = set
+}
@@ -0,0 +1,72 @@
+package cosmwasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+var (
+	codeA = CodeID("code a")
+	codeB = CodeID("code b")
+	codeC = CodeID("code c")
+)
+
+func TestCodeFilterAllowsByDefault(t *testing.T) {
+	w := Wasmer{}
+	require.NoError(t, w.filter.check(codeA))
+	require.NoError(t, w.filter.check(codeB))
+}
+
+func TestCodeFilterDenylist(t *testing.T) {
+	w := Wasmer{}
+	w.SetCodeDenylist([]CodeID{codeA})
+
+	err := w.filter.check(codeA)
+	require.Error(t, err)
+	assert.Equal(t, types.ContractPausedError{CodeID: codeA}, err)
+	require.NoError(t, w.filter.check(codeB))
+
+	// a denied code is rejected before reaching the vm
+	_, _, err = w.Execute(codeA, types.Env{}, []byte(`{}`), nil, GoAPI{}, nil, nil, 100000000)
+	require.IsType(t, types.ContractPausedError{}, err)
+	assert.Contains(t, err.Error(), "contract paused")
+	_, _, err = w.Query(codeA, []byte(`{}`), nil, GoAPI{}, nil, nil, 100000000)
+	require.IsType(t, types.ContractPausedError{}, err)
+}
+
+func TestCodeFilterAllowlist(t *testing.T) {
+	w := Wasmer{}
+	w.SetCodeAllowlist([]CodeID{codeA, codeB})
+	require.NoError(t, w.filter.check(codeA))
+	require.NoError(t, w.filter.check(codeB))
+	require.IsType(t, types.ContractPausedError{}, w.filter.check(codeC))
+
+	// deny wins over allow
+	w.SetCodeDenylist([]CodeID{codeB})
+	require.NoError(t, w.filter.check(codeA))
+	require.IsType(t, types.ContractPausedError{}, w.filter.check(codeB))
+}
+
+func TestCodeFilterSwap(t *testing.T) {
+	w := Wasmer{}
+	w.SetCodeDenylist([]CodeID{codeA})
+	require.Error(t, w.filter.check(codeA))
+
+	// swap the list at runtime
+	w.SetCodeDenylist([]CodeID{codeB})
+	require.NoError(t, w.filter.check(codeA))
+	require.Error(t, w.filter.check(codeB))
+
+	// and clear it again
+	w.SetCodeDenylist(nil)
+	require.NoError(t, w.filter.check(codeB))
+
+	w.SetCodeAllowlist([]CodeID{})
+	require.Error(t, w.filter.check(codeA))
+	w.SetCodeAllowlist(nil)
+	require.NoError(t, w.filter.check(codeA))
+}
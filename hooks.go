@@ -0,0 +1,32 @@
+package cosmwasm
+
+import (
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// PreCallHook is called before every contract call, see PreCall.
+// entryPoint is one of "init", "handle", "query" or "migrate". env is nil for queries.
+// Returning an error aborts the call before the contract is loaded.
+type PreCallHook func(code CodeID, entryPoint string, env *types.Env) error
+
+// PostCallHook is called after every contract call, see PostCall. result is the response of the call:
+// a *types.InitResponse, *types.HandleResponse or *types.MigrateResponse, or the []byte result of a query.
+// It is empty if err is set. gasUsed is the gas returned to the caller.
+type PostCallHook func(code CodeID, entryPoint string, env *types.Env, result interface{}, gasUsed uint64, err error)
+
+// preCall runs all PreCall hooks in order, stopping at the first error
+func (w *Wasmer) preCall(code CodeID, entryPoint string, env *types.Env) error {
+	for _, hook := range w.preCallHooks {
+		if err := hook(code, entryPoint, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postCall runs all PostCall hooks in order
+func (w *Wasmer) postCall(code CodeID, entryPoint string, env *types.Env, result interface{}, gasUsed uint64, err error) {
+	for _, hook := range w.postCallHooks {
+		hook(code, entryPoint, env, result, gasUsed, err)
+	}
+}
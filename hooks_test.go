@@ -0,0 +1,78 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+type hookCall struct {
+	entryPoint string
+	env        *types.Env
+	err        error
+}
+
+func TestCallHooksFire(t *testing.T) {
+	var pre, post []hookCall
+	w := Wasmer{}
+	PreCall(func(code CodeID, entryPoint string, env *types.Env) error {
+		pre = append(pre, hookCall{entryPoint: entryPoint, env: env})
+		return nil
+	})(&w)
+	PostCall(func(code CodeID, entryPoint string, env *types.Env, result interface{}, gasUsed uint64, err error) {
+		post = append(post, hookCall{entryPoint: entryPoint, env: env, err: err})
+	})(&w)
+
+	// a paused code never reaches the vm, but still passes through the hooks
+	w.SetCodeDenylist([]CodeID{codeA})
+	env := types.Env{Contract: types.ContractInfo{Address: "contract"}}
+	_, _, err := w.Execute(codeA, env, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	require.IsType(t, types.ContractPausedError{}, err)
+	_, _, err = w.Query(codeA, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	require.IsType(t, types.ContractPausedError{}, err)
+
+	require.Len(t, pre, 2)
+	assert.Equal(t, "handle", pre[0].entryPoint)
+	assert.Equal(t, &env, pre[0].env)
+	assert.Equal(t, "query", pre[1].entryPoint)
+	assert.Nil(t, pre[1].env)
+
+	require.Len(t, post, 2)
+	assert.Equal(t, "handle", post[0].entryPoint)
+	assert.IsType(t, types.ContractPausedError{}, post[0].err)
+	assert.Equal(t, "query", post[1].entryPoint)
+	assert.Nil(t, post[1].env)
+}
+
+func TestPreCallErrorShortCircuits(t *testing.T) {
+	var order []string
+	postCalled := false
+	w := Wasmer{}
+	PreCall(func(code CodeID, entryPoint string, env *types.Env) error {
+		order = append(order, "first")
+		return fmt.Errorf("spam from %s", env.Message.Sender)
+	})(&w)
+	PreCall(func(code CodeID, entryPoint string, env *types.Env) error {
+		order = append(order, "second")
+		return nil
+	})(&w)
+	PostCall(func(code CodeID, entryPoint string, env *types.Env, result interface{}, gasUsed uint64, err error) {
+		postCalled = true
+	})(&w)
+
+	// the zero Wasmer has no cache, so this would fail if the call got to the vm
+	env := types.Env{Message: types.MessageInfo{Sender: "spammer"}}
+	_, gasUsed, err := w.Execute(codeA, env, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	require.EqualError(t, err, "spam from spammer")
+	assert.Equal(t, uint64(0), gasUsed)
+	_, _, gasUsed, err = w.Instantiate(codeA, env, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	require.EqualError(t, err, "spam from spammer")
+	assert.Equal(t, uint64(0), gasUsed)
+
+	assert.Equal(t, []string{"first", "first"}, order)
+	assert.False(t, postCalled)
+}
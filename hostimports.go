@@ -0,0 +1,65 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// HostCategory is a group of host functionality that can be disabled with DisableHostCategories
+type HostCategory string
+
+const (
+	// HostIterator covers the db_scan and db_next imports used for iterating storage
+	HostIterator HostCategory = "iterator"
+	// HostQuery covers the query_chain import, i.e. all queries to other modules and contracts
+	HostQuery HostCategory = "query"
+	// HostStaking covers contracts requiring the staking feature (exporting requires_staking)
+	HostStaking HostCategory = "staking"
+)
+
+// hostCategoryImports lists the env imports belonging to each category
+var hostCategoryImports = map[HostCategory][]string{
+	HostIterator: {"db_scan", "db_next"},
+	HostQuery:    {"query_chain"},
+}
+
+// hostCategoryFeatures lists the required features belonging to each category
+var hostCategoryFeatures = map[HostCategory][]string{
+	HostStaking: {"staking"},
+}
+
+// checkHostImports returns an error if the code uses any of the disabled host categories
+func checkHostImports(code []byte, disabled []HostCategory) error {
+	if len(disabled) == 0 {
+		return nil
+	}
+	module, err := parseWasm(code)
+	if err != nil {
+		return err
+	}
+
+	imports := make(map[string]HostCategory)
+	features := make(map[string]HostCategory)
+	for _, c := range disabled {
+		for _, name := range hostCategoryImports[c] {
+			imports[name] = c
+		}
+		for _, name := range hostCategoryFeatures[c] {
+			features[name] = c
+		}
+	}
+
+	for _, imp := range module.Imports {
+		if c, ok := imports[imp.Name]; ok && imp.Module == "env" && imp.Kind == externFunc {
+			return fmt.Errorf("contract imports env.%s, but %s host functions are disabled on this chain", imp.Name, c)
+		}
+	}
+	required := module.requiredFeatures()
+	sort.Strings(required)
+	for _, f := range required {
+		if c, ok := features[f]; ok {
+			return fmt.Errorf("contract requires %s, but %s host functions are disabled on this chain", f, c)
+		}
+	}
+	return nil
+}
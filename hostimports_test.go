@@ -0,0 +1,38 @@
+package cosmwasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHostImports(t *testing.T) {
+	hackatom := readTestdata(t, "hackatom.wasm")
+	queue := readTestdata(t, "queue.wasm")
+	reflect := readTestdata(t, "reflect.wasm")
+
+	// nothing disabled, anything goes
+	require.NoError(t, checkHostImports(reflect, nil))
+
+	// reflect uses staking
+	w := Wasmer{}
+	DisableHostCategories(HostStaking)(&w)
+	err := checkHostImports(reflect, w.disabledHost)
+	require.Error(t, err)
+	assert.Equal(t, "contract requires staking, but staking host functions are disabled on this chain", err.Error())
+	require.NoError(t, checkHostImports(hackatom, w.disabledHost))
+	require.NoError(t, checkHostImports(queue, w.disabledHost))
+
+	// queue iterates
+	DisableHostCategories(HostIterator)(&w)
+	err = checkHostImports(queue, w.disabledHost)
+	require.Error(t, err)
+	assert.Equal(t, "contract imports env.db_scan, but iterator host functions are disabled on this chain", err.Error())
+	require.NoError(t, checkHostImports(hackatom, w.disabledHost))
+
+	// hackatom queries
+	err = checkHostImports(hackatom, []HostCategory{HostQuery})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "env.query_chain")
+}
@@ -0,0 +1,52 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// contractKeys remembers the contract keys retired by RotateContractKey.
+// It is safe for concurrent use, so keys can be rotated while other contracts run.
+type contractKeys struct {
+	mtx     sync.RWMutex
+	retired map[types.ContractKey]struct{}
+}
+
+func (k *contractKeys) check(key types.ContractKey) error {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+	if _, retired := k.retired[key]; retired {
+		return types.RotatedContractKeyError{}
+	}
+	return nil
+}
+
+// RotateContractKey replaces the key of a contract, e.g. after migrating its encryption.
+// From then on, every call whose Env carries the old key fails with a RotatedContractKeyError
+// before the contract is loaded, so the caller must pass the new key in the Env of all
+// subsequent calls. Re-encrypting the contract state is up to the caller.
+//
+// Retired keys are kept in memory only, the chain must rotate them again after a restart.
+func (w *Wasmer) RotateContractKey(oldKey, newKey types.ContractKey) error {
+	if oldKey == "" || newKey == "" {
+		return fmt.Errorf("contract keys must not be empty")
+	}
+	if oldKey == newKey {
+		return fmt.Errorf("new contract key must differ from the old one")
+	}
+	w.keys.mtx.Lock()
+	defer w.keys.mtx.Unlock()
+	if _, retired := w.keys.retired[oldKey]; retired {
+		return fmt.Errorf("contract key was rotated already")
+	}
+	if _, retired := w.keys.retired[newKey]; retired {
+		return fmt.Errorf("cannot rotate to a retired contract key")
+	}
+	if w.keys.retired == nil {
+		w.keys.retired = make(map[types.ContractKey]struct{})
+	}
+	w.keys.retired[oldKey] = struct{}{}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cosmwasm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+func TestRotateContractKey(t *testing.T) {
+	w := Wasmer{}
+	require.NoError(t, w.RotateContractKey("key-1", "key-2"))
+
+	// calls with the old key fail before they reach the vm
+	_, _, err := w.ExecuteContext(context.Background(), codeA, types.Env{Key: "key-1"}, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	assert.Equal(t, types.RotatedContractKeyError{}, err)
+	_, _, err = w.MigrateContext(context.Background(), codeA, types.Env{Key: "key-1"}, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	assert.Equal(t, types.RotatedContractKeyError{}, err)
+	// the new key is accepted
+	assert.NoError(t, w.keys.check("key-2"))
+
+	// and can be rotated again, which keeps all earlier keys retired
+	require.NoError(t, w.RotateContractKey("key-2", "key-3"))
+	assert.Equal(t, types.RotatedContractKeyError{}, w.keys.check("key-1"))
+	assert.Equal(t, types.RotatedContractKeyError{}, w.keys.check("key-2"))
+	assert.NoError(t, w.keys.check("key-3"))
+}
+
+func TestRotateContractKeyInvalid(t *testing.T) {
+	w := Wasmer{}
+	assert.EqualError(t, w.RotateContractKey("", "key-2"), "contract keys must not be empty")
+	assert.EqualError(t, w.RotateContractKey("key-1", ""), "contract keys must not be empty")
+	assert.EqualError(t, w.RotateContractKey("key-1", "key-1"), "new contract key must differ from the old one")
+
+	require.NoError(t, w.RotateContractKey("key-1", "key-2"))
+	assert.EqualError(t, w.RotateContractKey("key-1", "key-3"), "contract key was rotated already")
+	assert.EqualError(t, w.RotateContractKey("key-2", "key-1"), "cannot rotate to a retired contract key")
+}
@@ -1,8 +1,15 @@
 package cosmwasm
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
+	"unicode/utf8"
 
 	"github.com/enigmampc/SecretNetwork/go-cosmwasm/api"
 	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
@@ -31,6 +38,57 @@ type GasMeter = api.GasMeter
 // and call it for all cosmwasm code related actions.
 type Wasmer struct {
 	cache api.Cache
+	// directory the Wasmer keeps its state in
+	dataDir string
+	// accept responses with invalid utf-8 by replacing the bad bytes, instead of rejecting them
+	sanitizeUTF8 bool
+	// keep the raw json returned by the contract in the parsed responses
+	captureRaw bool
+	// which codes may be executed
+	filter codeFilter
+	// contract keys that were rotated out
+	keys contractKeys
+	// size of the worker pool used by ExecuteParallel
+	parallelWorkers int
+	// reuse responses of identical queries within one call, and whether to charge gas for them
+	cacheQueries        bool
+	chargeCachedQueries bool
+	// largest query response passed to contracts in bytes, 0 for no limit
+	maxQueryResponse int
+	// most iterators one call may open, 0 for no limit
+	maxIterators uint64
+	// optional receiver of metrics
+	metrics MetricsSink
+	// host functionality contracts may not use
+	disabledHost []HostCategory
+	// gas charged per byte of contract response
+	responseGasPerByte uint64
+	// pass sent funds to contracts exactly as given, instead of sorting and merging them
+	keepSentFunds bool
+	// normalize the funds sent by messages returned from contracts
+	normalizeMsgFunds bool
+	// keep the backtraces of vm errors, for simulations
+	captureBacktraces bool
+	// accept coin amounts of up to 256 instead of 128 bits
+	uint256Amounts bool
+	// gas refunded per storage delete, and the maximum refund in percent of gas used
+	refundPerDelete  uint64
+	refundCapPercent uint64
+	// return QueryFailedError for calls that failed after a query failed
+	reportQueryFailures bool
+	// prefix of the types of contract events, nil for the default
+	eventPrefix *string
+	// gas charged for storing code, nil for the defaults
+	storeGasCost *storeGasCost
+	// give up compiling code after this long, 0 for no limit
+	compileTimeout time.Duration
+	// replaces api.Create in tests
+	compiler compileFunc
+	// memory shared by all instances running at the same time, nil for no limit
+	memory *memoryBudget
+	// middleware run around every contract call
+	preCallHooks  []PreCallHook
+	postCallHooks []PostCallHook
 }
 
 // NewWasmer creates an new binding, with the given dataDir where
@@ -38,12 +96,17 @@ type Wasmer struct {
 // cacheSize sets the size of an optional in-memory LRU cache for prepared VMs.
 // They allow popular contracts to be executed very rapidly (no loading overhead),
 // but require ~32-64MB each in memory usage.
-func NewWasmer(dataDir string, supportedFeatures string, cacheSize uint64) (*Wasmer, error) {
+// Any opts are applied in order and change the default behaviour of the Wasmer.
+func NewWasmer(dataDir string, supportedFeatures string, cacheSize uint64, opts ...Option) (*Wasmer, error) {
 	cache, err := api.InitCache(dataDir, supportedFeatures, cacheSize)
 	if err != nil {
 		return nil, err
 	}
-	return &Wasmer{cache: cache}, nil
+	w := &Wasmer{cache: cache, dataDir: dataDir}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
 }
 
 // Cleanup should be called when no longer using this to free resources on the rust-side
@@ -62,7 +125,66 @@ func (w *Wasmer) Cleanup() {
 //
 // TODO: return gas cost? Add gas limit??? there is no metering here...
 func (w *Wasmer) Create(code WasmCode) (CodeID, error) {
-	return api.Create(w.cache, code)
+	start := time.Now()
+	id, err := w.compile(code)
+	w.compiled(id, len(code), start, err)
+	return id, err
+}
+
+// StoreResult is the result of storing code with StoreCode
+type StoreResult struct {
+	// Checksum is the CodeID to reference the stored code
+	Checksum CodeID
+	// Warnings about code that is valid, but possibly problematic (e.g. deprecated imports)
+	Warnings []string
+	// Size of the wasm code in bytes
+	Size uint64
+	// Gas to charge for storing the code, see EstimateStoreGas
+	Gas uint64
+}
+
+// StoreCode works like Create, but also returns some metadata about the stored code.
+// Callers only interested in the CodeID can ignore the other fields.
+//
+// Empty code or code without the wasm header is rejected up front with an ErrInvalidWasm.
+// Storing code that is already stored is a no-op: it is not compiled again, and the same
+// checksum is returned.
+//
+// Gzip compressed code is decompressed first, like EstimateStoreGas does, so the checksum,
+// size and gas are the ones of the wasm code itself.
+func (w *Wasmer) StoreCode(code WasmCode) (*StoreResult, error) {
+	code, err := decompressWasm(code)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := w.storeGas(code)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(code)
+	checksum := CodeID(hash[:])
+	if !w.hasCode(checksum, code) {
+		checksum, err = w.Create(code)
+		if err != nil {
+			return nil, err
+		}
+	}
+	warnings, err := codeWarnings(code)
+	if err != nil {
+		return nil, err
+	}
+	return &StoreResult{
+		Checksum: checksum,
+		Warnings: warnings,
+		Size:     uint64(len(code)),
+		Gas:      gas,
+	}, nil
+}
+
+// hasCode returns true if exactly this code is already stored under checksum (the sha256 of the code)
+func (w *Wasmer) hasCode(checksum CodeID, code WasmCode) bool {
+	stored, err := w.GetCode(checksum)
+	return err == nil && bytes.Equal(stored, code)
 }
 
 // GetCode will load the original wasm code for the given code id.
@@ -94,18 +216,95 @@ func (w *Wasmer) Instantiate(
 	gasMeter GasMeter,
 	gasLimit uint64,
 ) (*types.InitResponse, []byte, uint64, error) {
-	paramBin, err := json.Marshal(env)
+	return w.InstantiateContext(context.Background(), code, env, initMsg, store, goapi, querier, gasMeter, gasLimit)
+}
+
+// InstantiateContext is like Instantiate, but passes ctx on to the querier, store and GoAPI callbacks
+// if they support it (see ContextQuerier, ContextKVStore and GoAPI.BindContext).
+func (w *Wasmer) InstantiateContext(
+	ctx context.Context,
+	code CodeID,
+	env types.Env,
+	initMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.InitResponse, []byte, uint64, error) {
+	if err := w.preCall(code, "init", &env); err != nil {
+		return nil, nil, 0, err
+	}
+	querier, failures := w.trackQueryFailures(querier)
+	resp, key, gasUsed, err := w.instantiate(ctx, code, env, initMsg, store, goapi, querier, gasMeter, gasLimit)
+	err = failures.wrap(w.dropBacktrace(err))
+	w.postCall(code, "init", &env, resp, gasUsed, err)
+	return resp, key, gasUsed, err
+}
+
+// instantiate runs the init call, see Instantiate
+func (w *Wasmer) instantiate(
+	ctx context.Context,
+	code CodeID,
+	env types.Env,
+	initMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.InitResponse, []byte, uint64, error) {
+	if err := w.filter.check(code); err != nil {
+		return nil, nil, 0, err
+	}
+	ctx, release, err := w.reserveMemory(ctx)
 	if err != nil {
 		return nil, nil, 0, err
 	}
+	defer release()
+	if err := types.ValidateLabel(env.Contract.Label); err != nil {
+		return nil, nil, 0, err
+	}
+	if len(w.disabledHost) > 0 {
+		wasm, err := w.GetCode(code)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if err := checkHostImports(wasm, w.disabledHost); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	env, err = w.normalizeEnv(env)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	paramBin, err := w.encodeEnv(ctx, code, env)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	querier = w.wrapQuerier(ctx, querier)
+	store, goapi = bindContext(ctx, store, goapi)
+	store, deletes := w.trackDeletes(store)
+	store = api.LimitIterators(store, w.maxIterators)
+	gasMeter, querier, cancel, err := withCancellation(ctx, gasMeter, querier)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	start := time.Now()
 	data, gasUsed, err := api.Instantiate(w.cache, code, paramBin, initMsg, &gasMeter, store, &goapi, &querier, gasLimit)
+	err = cancel.wrap(err)
+	w.callCompleted("init", code, start, gasUsed, err)
+	if err != nil {
+		return nil, nil, gasUsed, err
+	}
+	gasUsed, err = w.chargeResponse(gasUsed, gasLimit, len(data)-64)
 	if err != nil {
 		return nil, nil, gasUsed, err
 	}
 
 	key := data[0:64]
 	var resp types.InitResult
-	err = json.Unmarshal(data[64:], &resp)
+	err = w.unmarshalResponse(data[64:], &resp)
 	if err != nil {
 		return nil, nil, gasUsed, err
 	}
@@ -113,6 +312,15 @@ func (w *Wasmer) Instantiate(
 	if resp.Err != nil {
 		return nil, nil, gasUsed, fmt.Errorf("%v", resp.Err)
 	}
+	if resp.Ok != nil {
+		if err := w.normalizeMessages(resp.Ok.Messages); err != nil {
+			return nil, nil, gasUsed, err
+		}
+	}
+	gasUsed = w.applyRefund(gasUsed, deletes)
+	if w.captureRaw && resp.Ok != nil {
+		resp.Ok.RawResponse = data[64:]
+	}
 	return resp.Ok, key, gasUsed, nil
 }
 
@@ -132,18 +340,86 @@ func (w *Wasmer) Execute(
 	gasMeter GasMeter,
 	gasLimit uint64,
 ) (*types.HandleResponse, uint64, error) {
-	paramBin, err := json.Marshal(env)
+	return w.ExecuteContext(context.Background(), code, env, executeMsg, store, goapi, querier, gasMeter, gasLimit)
+}
+
+// ExecuteContext is like Execute, but passes ctx on to the querier, store and GoAPI callbacks
+// if they support it (see ContextQuerier, ContextKVStore and GoAPI.BindContext).
+func (w *Wasmer) ExecuteContext(
+	ctx context.Context,
+	code CodeID,
+	env types.Env,
+	executeMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.HandleResponse, uint64, error) {
+	if err := w.preCall(code, "handle", &env); err != nil {
+		return nil, 0, err
+	}
+	querier, failures := w.trackQueryFailures(querier)
+	resp, gasUsed, err := w.execute(ctx, code, env, executeMsg, store, goapi, querier, gasMeter, gasLimit)
+	err = failures.wrap(w.dropBacktrace(err))
+	w.postCall(code, "handle", &env, resp, gasUsed, err)
+	return resp, gasUsed, err
+}
+
+// execute runs the handle call, see Execute
+func (w *Wasmer) execute(
+	ctx context.Context,
+	code CodeID,
+	env types.Env,
+	executeMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.HandleResponse, uint64, error) {
+	if err := w.filter.check(code); err != nil {
+		return nil, 0, err
+	}
+	if err := w.keys.check(env.Key); err != nil {
+		return nil, 0, err
+	}
+	ctx, release, err := w.reserveMemory(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+	env, err = w.normalizeEnv(env)
+	if err != nil {
+		return nil, 0, err
+	}
+	paramBin, err := w.encodeEnv(ctx, code, env)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	querier = w.wrapQuerier(ctx, querier)
+	store, goapi = bindContext(ctx, store, goapi)
+	store, deletes := w.trackDeletes(store)
+	store = api.LimitIterators(store, w.maxIterators)
+	gasMeter, querier, cancel, err := withCancellation(ctx, gasMeter, querier)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := time.Now()
 	data, gasUsed, err := api.Handle(w.cache, code, paramBin, executeMsg, &gasMeter, store, &goapi, &querier, gasLimit)
+	err = cancel.wrap(err)
+	w.callCompleted("handle", code, start, gasUsed, err)
+	if err != nil {
+		return nil, gasUsed, err
+	}
+	gasUsed, err = w.chargeResponse(gasUsed, gasLimit, len(data))
 	if err != nil {
 		return nil, gasUsed, err
 	}
 
 	var resp types.HandleResult
-	err = json.Unmarshal(data, &resp)
+	err = w.unmarshalResponse(data, &resp)
 
 	if err != nil {
 		return nil, gasUsed, err
@@ -152,6 +428,15 @@ func (w *Wasmer) Execute(
 	if resp.Err != nil {
 		return nil, gasUsed, fmt.Errorf("%v", resp.Err)
 	}
+	if resp.Ok != nil {
+		if err := w.normalizeMessages(resp.Ok.Messages); err != nil {
+			return nil, gasUsed, err
+		}
+	}
+	gasUsed = w.applyRefund(gasUsed, deletes)
+	if w.captureRaw && resp.Ok != nil {
+		resp.Ok.RawResponse = data
+	}
 
 	return resp.Ok, gasUsed, nil
 }
@@ -168,20 +453,126 @@ func (w *Wasmer) Query(
 	gasMeter GasMeter,
 	gasLimit uint64,
 ) ([]byte, uint64, error) {
+	return w.QueryContext(context.Background(), code, queryMsg, store, goapi, querier, gasMeter, gasLimit)
+}
+
+// QueryContext is like Query, but passes ctx on to the querier, store and GoAPI callbacks
+// if they support it (see ContextQuerier, ContextKVStore and GoAPI.BindContext).
+func (w *Wasmer) QueryContext(
+	ctx context.Context,
+	code CodeID,
+	queryMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) ([]byte, uint64, error) {
+	resp, gasUsed, err := w.runQuery(ctx, code, queryMsg, store, goapi, querier, gasMeter, gasLimit)
+	if err != nil {
+		return nil, gasUsed, err
+	}
+	return resp.Ok, gasUsed, nil
+}
+
+// runQuery runs a query call with everything the public methods apply around it: the call hooks,
+// query failure tracking and dropping the backtrace. If the vm ran the query, its response is
+// returned even if the contract answered with an error, which is then also returned.
+func (w *Wasmer) runQuery(
+	ctx context.Context,
+	code CodeID,
+	queryMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.QueryResponse, uint64, error) {
+	if err := w.preCall(code, "query", nil); err != nil {
+		return nil, 0, err
+	}
+	var result []byte
+	querier, failures := w.trackQueryFailures(querier)
+	resp, gasUsed, err := w.query(ctx, code, queryMsg, store, goapi, querier, gasMeter, gasLimit)
+	if err == nil {
+		if resp.Err != nil {
+			err = fmt.Errorf("%v", resp.Err)
+		} else {
+			result = resp.Ok
+		}
+	}
+	err = failures.wrap(w.dropBacktrace(err))
+	w.postCall(code, "query", nil, result, gasUsed, err)
+	return resp, gasUsed, err
+}
+
+// query runs the query and decodes the response, leaving it to the caller to handle a contract error
+func (w *Wasmer) query(
+	ctx context.Context,
+	code CodeID,
+	queryMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.QueryResponse, uint64, error) {
+	if err := w.filter.check(code); err != nil {
+		return nil, 0, err
+	}
+	ctx, release, err := w.reserveMemory(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+	querier = w.wrapQuerier(ctx, querier)
+	store, goapi = bindContext(ctx, store, goapi)
+	store = api.LimitIterators(store, w.maxIterators)
+	gasMeter, querier, cancel, err := withCancellation(ctx, gasMeter, querier)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := time.Now()
 	data, gasUsed, err := api.Query(w.cache, code, queryMsg, &gasMeter, store, &goapi, &querier, gasLimit)
+	err = cancel.wrap(err)
+	w.callCompleted("query", code, start, gasUsed, err)
+	if err != nil {
+		return nil, gasUsed, err
+	}
+	gasUsed, err = w.chargeResponse(gasUsed, gasLimit, len(data))
 	if err != nil {
 		return nil, gasUsed, err
 	}
 
-	var resp types.QueryResponse
-	err = json.Unmarshal(data, &resp)
+	resp, err := w.decodeQueryResponse(data)
 	if err != nil {
 		return nil, gasUsed, err
 	}
-	if resp.Err != nil {
-		return nil, gasUsed, fmt.Errorf("%v", resp.Err)
+	return resp, gasUsed, nil
+}
+
+// decodeQueryResponse decodes the response of a query like the other entry points do,
+// and prefixes the types of the events it contains
+func (w *Wasmer) decodeQueryResponse(data []byte) (*types.QueryResponse, error) {
+	var resp types.QueryResponse
+	if err := w.unmarshalResponse(data, &resp); err != nil {
+		return nil, err
 	}
-	return resp.Ok, gasUsed, nil
+	resp.Events = types.PrefixEventTypes(resp.Events, w.eventTypePrefix())
+	return &resp, nil
+}
+
+// QueryRaw reads the value stored under key in the contract's raw storage, without running the contract.
+// This lets operators and indexers inspect state directly. It returns nil if the key is not set.
+//
+// The store should be the same PrefixedKVStore that is passed to Instantiate and Execute for this contract.
+// If a gasMeter is supplied, the gas consumed by the read is returned.
+func (w *Wasmer) QueryRaw(
+	store KVStore,
+	key []byte,
+	gasMeter GasMeter,
+) ([]byte, uint64, error) {
+	return api.QueryRaw(store, key, &gasMeter)
 }
 
 // Migrate will migrate an existing contract to a new code binary.
@@ -200,22 +591,223 @@ func (w *Wasmer) Migrate(
 	gasMeter GasMeter,
 	gasLimit uint64,
 ) (*types.MigrateResponse, uint64, error) {
-	paramBin, err := json.Marshal(env)
+	return w.MigrateContext(context.Background(), code, env, migrateMsg, store, goapi, querier, gasMeter, gasLimit)
+}
+
+// MigrateContext is like Migrate, but passes ctx on to the querier, store and GoAPI callbacks
+// if they support it (see ContextQuerier, ContextKVStore and GoAPI.BindContext).
+func (w *Wasmer) MigrateContext(
+	ctx context.Context,
+	code CodeID,
+	env types.Env,
+	migrateMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.MigrateResponse, uint64, error) {
+	if err := w.preCall(code, "migrate", &env); err != nil {
+		return nil, 0, err
+	}
+	querier, failures := w.trackQueryFailures(querier)
+	resp, gasUsed, err := w.migrate(ctx, code, env, migrateMsg, store, goapi, querier, gasMeter, gasLimit)
+	err = failures.wrap(w.dropBacktrace(err))
+	w.postCall(code, "migrate", &env, resp, gasUsed, err)
+	return resp, gasUsed, err
+}
+
+// migrate runs the migrate call, see Migrate
+func (w *Wasmer) migrate(
+	ctx context.Context,
+	code CodeID,
+	env types.Env,
+	migrateMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.MigrateResponse, uint64, error) {
+	if err := w.filter.check(code); err != nil {
+		return nil, 0, err
+	}
+	if err := w.keys.check(env.Key); err != nil {
+		return nil, 0, err
+	}
+	ctx, release, err := w.reserveMemory(ctx)
 	if err != nil {
 		return nil, 0, err
 	}
+	defer release()
+	env, err = w.normalizeEnv(env)
+	if err != nil {
+		return nil, 0, err
+	}
+	paramBin, err := w.encodeEnv(ctx, code, env)
+	if err != nil {
+		return nil, 0, err
+	}
+	querier = w.wrapQuerier(ctx, querier)
+	store, goapi = bindContext(ctx, store, goapi)
+	store, deletes := w.trackDeletes(store)
+	store = api.LimitIterators(store, w.maxIterators)
+	gasMeter, querier, cancel, err := withCancellation(ctx, gasMeter, querier)
+	if err != nil {
+		return nil, 0, err
+	}
+	start := time.Now()
 	data, gasUsed, err := api.Migrate(w.cache, code, paramBin, migrateMsg, &gasMeter, store, &goapi, &querier, gasLimit)
+	err = cancel.wrap(err)
+	w.callCompleted("migrate", code, start, gasUsed, err)
+	if err != nil {
+		return nil, gasUsed, err
+	}
+	gasUsed, err = w.chargeResponse(gasUsed, gasLimit, len(data))
 	if err != nil {
 		return nil, gasUsed, err
 	}
 
 	var resp types.MigrateResult
-	err = json.Unmarshal(data, &resp)
+	err = w.unmarshalResponse(data, &resp)
 	if err != nil {
 		return nil, gasUsed, err
 	}
 	if resp.Err != nil {
 		return nil, gasUsed, fmt.Errorf("%v", resp.Err)
 	}
+	if resp.Ok != nil {
+		if err := w.normalizeMessages(resp.Ok.Messages); err != nil {
+			return nil, gasUsed, err
+		}
+	}
+	gasUsed = w.applyRefund(gasUsed, deletes)
+	if w.captureRaw && resp.Ok != nil {
+		resp.Ok.RawResponse = data
+	}
 	return resp.Ok, gasUsed, nil
 }
+
+// eventTypePrefix returns the prefix for the types of events emitted by contracts
+func (w *Wasmer) eventTypePrefix() string {
+	if w.eventPrefix == nil {
+		return types.DefaultEventTypePrefix
+	}
+	return *w.eventPrefix
+}
+
+// normalizeEnv checks the block info, and sorts and merges the sent funds unless the Wasmer keeps them as they are
+func (w *Wasmer) normalizeEnv(env types.Env) (types.Env, error) {
+	if err := env.Block.Validate(); err != nil {
+		return env, fmt.Errorf("invalid block info: %w", err)
+	}
+	if w.keepSentFunds {
+		return env, nil
+	}
+	funds, err := env.Message.SentFunds.NormalizeBits(w.amountBits())
+	if err != nil {
+		return env, fmt.Errorf("invalid sent funds: %w", err)
+	}
+	env.Message.SentFunds = funds
+	return env, nil
+}
+
+// dropBacktrace removes the (non-deterministic) backtrace from a vm error, unless the Wasmer captures them
+func (w *Wasmer) dropBacktrace(err error) error {
+	if vmErr, ok := err.(types.VMError); ok && !w.captureBacktraces {
+		return types.VMError{Msg: vmErr.Msg}
+	}
+	return err
+}
+
+// amountBits returns the width of valid coin amounts, see AllowUint256Amounts
+func (w *Wasmer) amountBits() uint {
+	if w.uint256Amounts {
+		return types.Uint256Bits
+	}
+	return types.Uint128Bits
+}
+
+// normalizeMessages normalizes the funds sent by the messages a contract returned,
+// if the Wasmer was configured to do so
+func (w *Wasmer) normalizeMessages(msgs []types.CosmosMsg) error {
+	if !w.normalizeMsgFunds {
+		return nil
+	}
+	if err := types.NormalizeFundsBits(msgs, w.amountBits()); err != nil {
+		return fmt.Errorf("invalid funds in contract response: %w", err)
+	}
+	return nil
+}
+
+// chargeResponse adds the gas for a response of size bytes to gasUsed.
+// If this exceeds gasLimit, all gas is used up and an OutOfGasError is returned.
+func (w *Wasmer) chargeResponse(gasUsed uint64, gasLimit uint64, size int) (uint64, error) {
+	if w.responseGasPerByte == 0 || size <= 0 {
+		return gasUsed, nil
+	}
+	remaining := uint64(0)
+	if gasLimit > gasUsed {
+		remaining = gasLimit - gasUsed
+	}
+	if uint64(size) > remaining/w.responseGasPerByte {
+		return gasLimit, types.OutOfGasError{}
+	}
+	return gasUsed + uint64(size)*w.responseGasPerByte, nil
+}
+
+// wrapQuerier sets up the querier for one vm call
+func (w *Wasmer) wrapQuerier(ctx context.Context, querier Querier) Querier {
+	querier = withContext(ctx, querier)
+	if w.maxQueryResponse > 0 && querier != nil {
+		querier = queryResponseLimit{Querier: querier, maxSize: w.maxQueryResponse}
+	}
+	if w.cacheQueries && querier != nil {
+		querier = newQueryCache(querier, w.chargeCachedQueries)
+	}
+	return querier
+}
+
+// unmarshalResponse decodes the json returned by a contract into resp.
+// Errors are wrapped in a ResponseDecodeError with the path of the offending value.
+// If the Wasmer captures raw responses, a malformed response is returned inside
+// an InvalidResponse error, so the raw bytes can be inspected.
+func (w *Wasmer) unmarshalResponse(data []byte, resp interface{}) error {
+	if err := w.checkUTF8(data); err != nil {
+		return err
+	}
+	err := json.Unmarshal(data, resp)
+	if err == nil {
+		return nil
+	}
+	path := types.ErrorPath(data, resp)
+	// binary fields (e.g. data) are decoded by encoding/json, which reports bad base64 as is
+	var corrupt base64.CorruptInputError
+	if errors.As(err, &corrupt) {
+		return types.InvalidBase64{Msg: fmt.Sprintf("contract response contains invalid base64 at byte %d of binary field %s", int64(corrupt), path)}
+	}
+	if path != "" {
+		err = types.ResponseDecodeError{Path: path, Err: err}
+	}
+	if w.captureRaw {
+		return types.InvalidResponse{Err: err.Error(), Response: data}
+	}
+	return err
+}
+
+// checkUTF8 rejects a raw contract response containing invalid UTF-8, unless the Wasmer
+// was configured to sanitize it. Outside of strings, the json encoding is pure ascii,
+// so any invalid byte must come from a string the contract returned (e.g. a log attribute).
+func (w *Wasmer) checkUTF8(data []byte) error {
+	if w.sanitizeUTF8 || utf8.Valid(data) {
+		return nil
+	}
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			return types.InvalidUtf8{Msg: fmt.Sprintf("contract response contains invalid utf-8 at byte %d", i)}
+		}
+		i += size
+	}
+	return nil
+}
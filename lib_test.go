@@ -0,0 +1,282 @@
+package cosmwasm
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+func TestInstantiateRejectsLongLabel(t *testing.T) {
+	// the label is checked before we ever reach the vm
+	w := Wasmer{}
+	env := types.Env{
+		Contract: types.ContractInfo{
+			Address: "contract",
+			Label:   strings.Repeat("x", types.MaxLabelSize+1),
+		},
+	}
+	_, _, _, err := w.Instantiate(nil, env, []byte(`{}`), nil, GoAPI{}, nil, nil, 100000000)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "label too long")
+}
+
+func TestCheckUTF8(t *testing.T) {
+	valid := []byte(`{"Ok":{"messages":[],"log":[{"key":"action","value":"✓"}]}}`)
+	invalid := []byte("{\"Ok\":{\"messages\":[],\"log\":[{\"key\":\"action\",\"value\":\"bad \xff\xfe\"}]}}")
+
+	// by default, invalid utf-8 is rejected
+	w := Wasmer{}
+	require.NoError(t, w.checkUTF8(valid))
+	err := w.checkUTF8(invalid)
+	require.Error(t, err)
+	require.IsType(t, types.InvalidUtf8{}, err)
+	assert.Contains(t, err.Error(), "invalid utf-8 at byte 57")
+
+	// when sanitizing, the bad bytes are replaced during decoding
+	SanitizeInvalidUTF8()(&w)
+	require.NoError(t, w.checkUTF8(invalid))
+	var resp types.HandleResult
+	err = json.Unmarshal(invalid, &resp)
+	require.NoError(t, err)
+	require.NotNil(t, resp.Ok)
+	require.Equal(t, 1, len(resp.Ok.Log))
+	assert.Equal(t, "bad ��", resp.Ok.Log[0].Value)
+}
+
+func TestDecodeQueryResponse(t *testing.T) {
+	invalid := []byte("{\"Ok\":\"e30=\",\"events\":[{\"type\":\"trace\",\"attributes\":[{\"key\":\"k\",\"value\":\"bad \xff\"}]}]}")
+
+	// query events get the same utf-8 checks as all other responses
+	w := Wasmer{}
+	_, err := w.decodeQueryResponse(invalid)
+	require.IsType(t, types.InvalidUtf8{}, err)
+
+	SanitizeInvalidUTF8()(&w)
+	resp, err := w.decodeQueryResponse(invalid)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("{}"), resp.Ok)
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, "wasm-trace", resp.Events[0].Type)
+	assert.Equal(t, "bad \ufffd", resp.Events[0].Attributes[0].Value)
+
+	// and decoding errors carry their path
+	_, err = w.decodeQueryResponse([]byte(`{"Ok":"e30=","events":[{"type":7}]}`))
+	var decodeErr types.ResponseDecodeError
+	require.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, "events[0].type", decodeErr.Path)
+}
+
+func TestUnmarshalResponseInvalidBase64(t *testing.T) {
+	valid := []byte(`{"Ok":{"messages":[],"log":[],"data":"aGVsbG8="}}`)
+	invalid := []byte(`{"Ok":{"messages":[],"log":[],"data":"not base64!"}}`)
+
+	w := Wasmer{}
+	var resp types.HandleResult
+	require.NoError(t, w.unmarshalResponse(valid, &resp))
+	assert.Equal(t, []byte("hello"), resp.Ok.Data)
+
+	err := w.unmarshalResponse(invalid, &resp)
+	require.Error(t, err)
+	require.IsType(t, types.InvalidBase64{}, err)
+	assert.Contains(t, err.Error(), "invalid base64 at byte 3")
+
+	// this takes precedence over capturing the raw response
+	CaptureRawResponses()(&w)
+	err = w.unmarshalResponse(invalid, &resp)
+	require.IsType(t, types.InvalidBase64{}, err)
+}
+
+func TestUnmarshalResponseRaw(t *testing.T) {
+	malformed := []byte(`{"Ok":{"messages":[{"bank":{"send":{"amount":17}}}]}}`)
+
+	// by default, we just get the json error and where it happened
+	w := Wasmer{}
+	var resp types.HandleResult
+	err := w.unmarshalResponse(malformed, &resp)
+	require.Error(t, err)
+	var typeErr *json.UnmarshalTypeError
+	require.True(t, errors.As(err, &typeErr))
+	assert.Equal(t, "Ok.messages[0].bank.send.amount", err.(types.ResponseDecodeError).Path)
+
+	// when capturing, the raw bytes come back with the error
+	CaptureRawResponses()(&w)
+	err = w.unmarshalResponse(malformed, &resp)
+	require.Error(t, err)
+	invalid, ok := err.(types.InvalidResponse)
+	require.True(t, ok)
+	assert.Equal(t, malformed, invalid.Response)
+	assert.Contains(t, invalid.Err, "cannot unmarshal number")
+	assert.Contains(t, invalid.Err, "at Ok.messages[0].bank.send.amount")
+}
+
+func TestChargeResponse(t *testing.T) {
+	// free by default
+	w := Wasmer{}
+	gas, err := w.chargeResponse(1000, 5000, 100000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), gas)
+
+	ResponseGasPerByte(3)(&w)
+	gas, err = w.chargeResponse(1000, 5000, 100)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1300), gas)
+
+	// exactly the remaining gas is fine
+	gas, err = w.chargeResponse(1000, 5000, 1333)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4999), gas)
+
+	// a large response runs out of gas
+	gas, err = w.chargeResponse(1000, 5000, 1334)
+	require.Error(t, err)
+	assert.IsType(t, types.OutOfGasError{}, err)
+	assert.Equal(t, uint64(5000), gas)
+
+	// no overflow on absurd sizes
+	ResponseGasPerByte(1 << 62)(&w)
+	_, err = w.chargeResponse(0, 1<<63, 4)
+	require.Error(t, err)
+}
+
+func TestNormalizeEnv(t *testing.T) {
+	env := types.Env{
+		Message: types.MessageInfo{
+			Sender: "sender",
+			SentFunds: types.Coins{
+				{Denom: "uscrt", Amount: "100"},
+				{Denom: "ufoo", Amount: "7"},
+				{Denom: "uscrt", Amount: "23"},
+			},
+		},
+	}
+
+	w := Wasmer{}
+	normalized, err := w.normalizeEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, types.Coins{{Denom: "ufoo", Amount: "7"}, {Denom: "uscrt", Amount: "123"}}, normalized.Message.SentFunds)
+
+	// bad amounts never reach the contract
+	bad := env
+	bad.Message.SentFunds = types.Coins{{Denom: "uscrt", Amount: "-1"}}
+	_, err = w.normalizeEnv(bad)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid sent funds")
+
+	// amounts beyond 128 bits need to be allowed
+	big := env
+	big.Message.SentFunds = types.Coins{{Denom: "weth", Amount: "340282366920938463463374607431768211456"}}
+	_, err = w.normalizeEnv(big)
+	require.Error(t, err)
+	AllowUint256Amounts()(&w)
+	normalized, err = w.normalizeEnv(big)
+	require.NoError(t, err)
+	assert.Equal(t, big.Message.SentFunds, normalized.Message.SentFunds)
+
+	// unless we keep them as they are
+	KeepSentFundsOrder()(&w)
+	kept, err := w.normalizeEnv(env)
+	require.NoError(t, err)
+	assert.Equal(t, env, kept)
+
+	// a random beacon of the wrong size never reaches the contract, whatever the options
+	short := env
+	short.Block.Random = []byte("too short")
+	_, err = w.normalizeEnv(short)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "block random must be 32 bytes, got 9")
+	short.Block.Random = make([]byte, types.RandomLength)
+	_, err = w.normalizeEnv(short)
+	require.NoError(t, err)
+}
+
+func TestNormalizeMessages(t *testing.T) {
+	send := func() []types.CosmosMsg {
+		return []types.CosmosMsg{{Bank: &types.BankMsg{Send: &types.SendMsg{Amount: types.Coins{
+			{Denom: "uscrt", Amount: "1"},
+			{Denom: "ufoo", Amount: "0"},
+			{Denom: "uscrt", Amount: "2"},
+		}}}}}
+	}
+
+	// off by default
+	w := Wasmer{}
+	msgs := send()
+	require.NoError(t, w.normalizeMessages(msgs))
+	assert.Equal(t, send(), msgs)
+
+	NormalizeMessageFunds()(&w)
+	require.NoError(t, w.normalizeMessages(msgs))
+	assert.Equal(t, types.Coins{{Denom: "uscrt", Amount: "3"}}, msgs[0].Bank.Send.Amount)
+
+	msgs[0].Bank.Send.Amount = types.Coins{{Denom: "uscrt", Amount: "abc"}}
+	err := w.normalizeMessages(msgs)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid funds in contract response")
+}
+
+func TestStoreCodeIsIdempotent(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "go-cosmwasm")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	sink := &recordingSink{}
+	w, err := NewWasmer(tmpdir, "staking", 3, WithMetrics(sink))
+	require.NoError(t, err)
+	defer w.Cleanup()
+
+	code := readTestdata(t, "hackatom.wasm")
+	first, err := w.StoreCode(code)
+	require.NoError(t, err)
+	require.Len(t, sink.compiles, 1)
+	// the checksum is the sha256 of the code, which is what lets us look it up before compiling
+	hash := sha256.Sum256(code)
+	assert.Equal(t, CodeID(hash[:]), first.Checksum)
+
+	// the second time, nothing is compiled
+	second, err := w.StoreCode(code)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Len(t, sink.compiles, 1)
+
+	// other code still is
+	_, err = w.StoreCode(readTestdata(t, "queue.wasm"))
+	require.NoError(t, err)
+	assert.Len(t, sink.compiles, 2)
+}
+
+func TestEventTypePrefix(t *testing.T) {
+	w := Wasmer{}
+	assert.Equal(t, "wasm-", w.eventTypePrefix())
+
+	EventTypePrefix("contract-")(&w)
+	assert.Equal(t, "contract-", w.eventTypePrefix())
+
+	EventTypePrefix("")(&w)
+	assert.Equal(t, "", w.eventTypePrefix())
+}
+
+func TestDropBacktrace(t *testing.T) {
+	trap := types.VMError{Msg: "Error executing Wasm: RuntimeError: unreachable", Backtrace: "stack backtrace:\n   0: go_cosmwasm::handle"}
+
+	// consensus mode keeps only the deterministic message
+	w := Wasmer{}
+	assert.Equal(t, types.VMError{Msg: trap.Msg}, w.dropBacktrace(trap))
+	other := fmt.Errorf("other")
+	assert.Equal(t, other, w.dropBacktrace(other))
+	assert.Nil(t, w.dropBacktrace(nil))
+
+	// simulations may capture it
+	CaptureBacktraces()(&w)
+	err := w.dropBacktrace(trap)
+	assert.Equal(t, trap, err)
+	assert.EqualError(t, err, trap.Msg)
+}
@@ -0,0 +1,62 @@
+package cosmwasm
+
+import (
+	"context"
+	"fmt"
+)
+
+// memoryBudget bounds the memory of all instances running at the same time, see MemoryBudget.
+// Every call reserves the same amount, so this is a semaphore with total/perInstance slots.
+type memoryBudget struct {
+	total       uint64
+	perInstance uint64
+	slots       chan struct{}
+}
+
+func newMemoryBudget(total uint64, perInstance uint64) *memoryBudget {
+	n := uint64(0)
+	if perInstance > 0 {
+		n = total / perInstance
+	}
+	return &memoryBudget{
+		total:       total,
+		perInstance: perInstance,
+		slots:       make(chan struct{}, n),
+	}
+}
+
+// reserve blocks until the memory for one instance is available, or ctx is done.
+// The returned function releases the reservation.
+func (b *memoryBudget) reserve(ctx context.Context) (func(), error) {
+	if cap(b.slots) == 0 {
+		return nil, fmt.Errorf("memory budget of %d bytes cannot fit an instance of %d bytes", b.total, b.perInstance)
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return func() { <-b.slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for memory budget: %w", ctx.Err())
+	}
+}
+
+// memoryReserved marks the context of a call holding a reservation of the budget it maps to
+type memoryReserved struct{}
+
+// reserveMemory reserves the memory for one instance, if the Wasmer has a memory budget.
+// The returned context marks the reservation. It is what a ContextQuerier gets to see, so
+// nested calls made with it while serving a query (e.g. a keeper answering a wasm smart
+// query with Wasmer.QueryContext) run on the reservation of the calling instance, instead
+// of waiting for a slot it holds itself.
+func (w *Wasmer) reserveMemory(ctx context.Context) (context.Context, func(), error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if w.memory == nil || ctx.Value(memoryReserved{}) == w.memory {
+		return ctx, func() {}, nil
+	}
+	release, err := w.memory.reserve(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return context.WithValue(ctx, memoryReserved{}, w.memory), release, nil
+}
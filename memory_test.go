@@ -0,0 +1,123 @@
+package cosmwasm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+func TestMemoryBudgetSaturated(t *testing.T) {
+	w := Wasmer{}
+	MemoryBudget(3<<20, 1<<20)(&w)
+
+	// saturate the budget from several goroutines
+	releases := make(chan func(), 3)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, release, err := w.reserveMemory(context.Background())
+			if assert.NoError(t, err) {
+				releases <- release
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the next call blocks until its context is done, before it reaches the vm
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err := w.ExecuteContext(ctx, codeA, types.Env{}, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// releasing an instance lets a waiting one through
+	reserved := make(chan struct{})
+	go func() {
+		_, release, err := w.reserveMemory(context.Background())
+		if assert.NoError(t, err) {
+			close(reserved)
+			release()
+		}
+	}()
+	select {
+	case <-reserved:
+		t.Fatal("reserved memory beyond the budget")
+	case <-time.After(20 * time.Millisecond):
+	}
+	(<-releases)()
+	select {
+	case <-reserved:
+	case <-time.After(time.Second):
+		t.Fatal("released memory was not handed on")
+	}
+}
+
+func TestMemoryBudgetTooSmall(t *testing.T) {
+	w := Wasmer{}
+	MemoryBudget(1<<20, 2<<20)(&w)
+	_, _, err := w.QueryContext(context.Background(), codeA, []byte(`{}`), nil, GoAPI{}, nil, nil, 1000)
+	require.EqualError(t, err, "memory budget of 1048576 bytes cannot fit an instance of 2097152 bytes")
+}
+
+// nestedQuerier answers queries like a keeper would, with a nested call into the Wasmer,
+// which starts by reserving memory
+type nestedQuerier struct {
+	w *Wasmer
+}
+
+func (q nestedQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	return q.QueryContext(context.Background(), request, gasLimit)
+}
+
+func (q nestedQuerier) QueryContext(ctx context.Context, request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	_, release, err := q.w.reserveMemory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	release()
+	return []byte(`{}`), nil
+}
+
+func (q nestedQuerier) GasConsumed() uint64 {
+	return 0
+}
+
+func TestMemoryBudgetNestedQuery(t *testing.T) {
+	w := Wasmer{}
+	MemoryBudget(1<<20, 1<<20)(&w)
+	ReportQueryFailures()(&w)
+
+	// the outer call holds the only slot, and its contract queries another one
+	ctx, release, err := w.reserveMemory(context.Background())
+	require.NoError(t, err)
+	defer release()
+	querier, _ := w.trackQueryFailures(nestedQuerier{w: &w})
+	querier = w.wrapQuerier(ctx, querier)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := querier.Query(smartQuery("other"), 1000)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("nested query deadlocked on the memory budget")
+	}
+
+	// unrelated calls still have to wait
+	waitCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err = w.reserveMemory(waitCtx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
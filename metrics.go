@@ -0,0 +1,30 @@
+package cosmwasm
+
+import (
+	"time"
+)
+
+// MetricsSink receives metrics about the work done by a Wasmer, e.g. to export them to prometheus.
+// Set one with WithMetrics. Methods are called synchronously, so they should return quickly.
+//
+// Note there is no hook for the module cache: the vm currently keeps no in-memory cache of
+// prepared instances (the cacheSize of NewWasmer is unused), so every call loads the module.
+type MetricsSink interface {
+	// Compiled is called after Create compiled (and stored) some code
+	Compiled(code CodeID, size int, duration time.Duration, err error)
+	// CallCompleted is called when a call into a contract returns. entryPoint is one of
+	// "init", "handle", "query" or "migrate". gasUsed is the gas reported by the vm.
+	CallCompleted(entryPoint string, code CodeID, duration time.Duration, gasUsed uint64, err error)
+}
+
+func (w *Wasmer) compiled(code CodeID, size int, start time.Time, err error) {
+	if w.metrics != nil {
+		w.metrics.Compiled(code, size, time.Since(start), err)
+	}
+}
+
+func (w *Wasmer) callCompleted(entryPoint string, code CodeID, start time.Time, gasUsed uint64, err error) {
+	if w.metrics != nil {
+		w.metrics.CallCompleted(entryPoint, code, time.Since(start), gasUsed, err)
+	}
+}
@@ -0,0 +1,65 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+type recordedCall struct {
+	entryPoint string
+	code       CodeID
+	gasUsed    uint64
+	err        error
+}
+
+type recordingSink struct {
+	compiles []CodeID
+	calls    []recordedCall
+}
+
+var _ MetricsSink = (*recordingSink)(nil)
+
+func (s *recordingSink) Compiled(code CodeID, size int, duration time.Duration, err error) {
+	s.compiles = append(s.compiles, code)
+}
+
+func (s *recordingSink) CallCompleted(entryPoint string, code CodeID, duration time.Duration, gasUsed uint64, err error) {
+	s.calls = append(s.calls, recordedCall{entryPoint: entryPoint, code: code, gasUsed: gasUsed, err: err})
+}
+
+func TestMetricsHooks(t *testing.T) {
+	// without a sink, this is a no-op
+	w := Wasmer{}
+	w.compiled(codeA, 123, time.Now(), nil)
+	w.callCompleted("handle", codeA, time.Now(), 5000, nil)
+
+	sink := &recordingSink{}
+	WithMetrics(sink)(&w)
+	w.compiled(codeA, 123, time.Now(), nil)
+	w.callCompleted("init", codeA, time.Now(), 1234, nil)
+	w.callCompleted("handle", codeB, time.Now(), 5000, fmt.Errorf("out of gas"))
+
+	require.Equal(t, []CodeID{codeA}, sink.compiles)
+	require.Len(t, sink.calls, 2)
+	assert.Equal(t, recordedCall{entryPoint: "init", code: codeA, gasUsed: 1234}, sink.calls[0])
+	assert.Equal(t, "handle", sink.calls[1].entryPoint)
+	assert.Equal(t, uint64(5000), sink.calls[1].gasUsed)
+	assert.EqualError(t, sink.calls[1].err, "out of gas")
+}
+
+func TestMetricsNotReportedForRejectedCalls(t *testing.T) {
+	// calls rejected before reaching the vm do not count as completed
+	sink := &recordingSink{}
+	w := Wasmer{}
+	WithMetrics(sink)(&w)
+	w.SetCodeDenylist([]CodeID{codeA})
+	_, _, err := w.Execute(codeA, types.Env{}, []byte(`{}`), nil, GoAPI{}, nil, nil, 100000000)
+	require.Error(t, err)
+	assert.Empty(t, sink.calls)
+}
@@ -0,0 +1,52 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckMigration statically checks that a contract running oldCode can be migrated to newCode,
+// before any migration is attempted: the new code must export migrate, and all features it
+// requires must be in enabledCaps (usually the supportedFeatures the Wasmer was created with).
+// It returns a descriptive error listing all incompatibilities.
+func (w *Wasmer) CheckMigration(oldCode, newCode CodeID, enabledCaps []string) error {
+	if _, err := w.GetCode(oldCode); err != nil {
+		return fmt.Errorf("cannot load old code: %w", err)
+	}
+	code, err := w.GetCode(newCode)
+	if err != nil {
+		return fmt.Errorf("cannot load new code: %w", err)
+	}
+	return checkMigration(code, enabledCaps)
+}
+
+// checkMigration runs the static migration checks on the wasm of the new code
+func checkMigration(newCode []byte, enabledCaps []string) error {
+	module, err := parseWasm(newCode)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	if !module.hasExport("migrate") {
+		problems = append(problems, "new code does not export migrate")
+	}
+	enabled := make(map[string]bool, len(enabledCaps))
+	for _, c := range enabledCaps {
+		enabled[strings.TrimSpace(c)] = true
+	}
+	var missing []string
+	for _, f := range module.requiredFeatures() {
+		if !enabled[f] {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("new code requires unavailable capabilities: %s", strings.Join(missing, ", ")))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("incompatible migration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package cosmwasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMigration(t *testing.T) {
+	hackatom := readTestdata(t, "hackatom.wasm")
+	queue := readTestdata(t, "queue.wasm")
+	reflect := readTestdata(t, "reflect.wasm")
+
+	// hackatom exports migrate and needs no capabilities
+	require.NoError(t, checkMigration(hackatom, nil))
+	require.NoError(t, checkMigration(hackatom, []string{"staking"}))
+
+	// queue cannot be migrated to
+	err := checkMigration(queue, []string{"staking"})
+	require.Error(t, err)
+	assert.Equal(t, "incompatible migration: new code does not export migrate", err.Error())
+
+	// reflect also requires staking
+	err = checkMigration(reflect, nil)
+	require.Error(t, err)
+	assert.Equal(t, "incompatible migration: new code does not export migrate; new code requires unavailable capabilities: staking", err.Error())
+	err = checkMigration(reflect, []string{"staking"})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "capabilities")
+
+	// garbage is not wasm at all
+	err = checkMigration([]byte("foo"), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid wasm")
+}
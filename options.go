@@ -0,0 +1,209 @@
+package cosmwasm
+
+import "time"
+
+// Option configures optional behaviour of a Wasmer, see NewWasmer
+type Option func(*Wasmer)
+
+// SanitizeInvalidUTF8 makes the Wasmer accept contract responses that contain invalid UTF-8
+// (for example in log attributes), replacing the invalid bytes with U+FFFD.
+// By default, such responses are rejected with an InvalidUtf8 error, as they would break
+// the ABCI event encoding downstream.
+func SanitizeInvalidUTF8() Option {
+	return func(w *Wasmer) {
+		w.sanitizeUTF8 = true
+	}
+}
+
+// CaptureRawResponses makes the Wasmer keep the raw json returned by the contract
+// in the RawResponse field of the parsed response, for auditing. If the response cannot be
+// parsed, the raw bytes are returned inside a types.InvalidResponse error.
+// RawResponse is nil unless this option is set.
+func CaptureRawResponses() Option {
+	return func(w *Wasmer) {
+		w.captureRaw = true
+	}
+}
+
+// ParallelWorkers sets the number of workers ExecuteParallel uses.
+// The default (or any n <= 0) is one worker per cpu.
+func ParallelWorkers(n int) Option {
+	return func(w *Wasmer) {
+		w.parallelWorkers = n
+	}
+}
+
+// CacheQueries makes identical queries issued by a contract during one call reuse the
+// first response, instead of asking the querier again. The cache is dropped when the call returns.
+// If chargeRepeated is set, every cached response costs the gas of the original query,
+// otherwise repeated queries are free.
+func CacheQueries(chargeRepeated bool) Option {
+	return func(w *Wasmer) {
+		w.cacheQueries = true
+		w.chargeCachedQueries = chargeRepeated
+	}
+}
+
+// WithMetrics makes the Wasmer report compiles and contract calls to sink
+func WithMetrics(sink MetricsSink) Option {
+	return func(w *Wasmer) {
+		w.metrics = sink
+	}
+}
+
+// MaxQueryResponseSize limits the responses to queries made by contracts to maxSize bytes.
+// Larger responses fail the query with an InvalidResponse error instead of being handed to the
+// contract, so a queried contract cannot make the caller run out of memory.
+func MaxQueryResponseSize(maxSize int) Option {
+	return func(w *Wasmer) {
+		w.maxQueryResponse = maxSize
+	}
+}
+
+// ReportQueryFailures makes contract calls that fail after one of the contract's queries failed
+// return a types.QueryFailedError. It tells whether the host could not answer the query (e.g. the
+// queried contract does not exist) or the query was answered with an error (e.g. the queried
+// contract errored), and unwraps to the original error of the call.
+func ReportQueryFailures() Option {
+	return func(w *Wasmer) {
+		w.reportQueryFailures = true
+	}
+}
+
+// EventTypePrefix sets the prefix added to the type of every event emitted by a contract, so contract
+// events never collide with events of native modules. It defaults to types.DefaultEventTypePrefix;
+// pass "" to keep the types as emitted.
+func EventTypePrefix(prefix string) Option {
+	return func(w *Wasmer) {
+		w.eventPrefix = &prefix
+	}
+}
+
+type storeGasCost struct {
+	perByte     uint64
+	perFunction uint64
+}
+
+// StoreGasCost sets the gas StoreCode and EstimateStoreGas charge for storing code:
+// perByte for every byte of the decompressed code, plus perFunction for every function it defines.
+func StoreGasCost(perByte uint64, perFunction uint64) Option {
+	return func(w *Wasmer) {
+		w.storeGasCost = &storeGasCost{perByte: perByte, perFunction: perFunction}
+	}
+}
+
+// PreCall adds a hook run before every Instantiate, Execute, Query and Migrate call (e.g. for antispam).
+// If it returns an error, the call fails with it before the contract is loaded. Hooks run in the
+// order they were added and must be safe for concurrent use if ExecuteParallel is used.
+func PreCall(hook PreCallHook) Option {
+	return func(w *Wasmer) {
+		w.preCallHooks = append(w.preCallHooks, hook)
+	}
+}
+
+// PostCall adds a hook run after every Instantiate, Execute, Query and Migrate call that passed the
+// PreCall hooks, with the result and gas used. Hooks run in the order they were added and must be
+// safe for concurrent use if ExecuteParallel is used.
+func PostCall(hook PostCallHook) Option {
+	return func(w *Wasmer) {
+		w.postCallHooks = append(w.postCallHooks, hook)
+	}
+}
+
+// DisableHostCategories makes Instantiate reject contracts using any host functionality of the
+// given categories, with a clear error instead of a failure at runtime.
+// Note this loads and inspects the code on every Instantiate.
+func DisableHostCategories(categories ...HostCategory) Option {
+	return func(w *Wasmer) {
+		w.disabledHost = append(w.disabledHost, categories...)
+	}
+}
+
+// ResponseGasPerByte charges gas for every byte of a contract response (messages, data and logs),
+// on top of the gas used for the execution itself. If the response cannot be paid for with the
+// remaining gas, the call fails with an OutOfGasError.
+func ResponseGasPerByte(cost uint64) Option {
+	return func(w *Wasmer) {
+		w.responseGasPerByte = cost
+	}
+}
+
+// KeepSentFundsOrder makes the Wasmer pass MessageInfo.SentFunds to contracts exactly as given.
+// By default, they are sorted by denom and duplicate denoms are merged, as contracts expect.
+func KeepSentFundsOrder() Option {
+	return func(w *Wasmer) {
+		w.keepSentFunds = true
+	}
+}
+
+// NormalizeMessageFunds makes the Wasmer normalize the coins sent by bank sends and wasm messages
+// returned from contracts: zero amounts are dropped, duplicate denoms merged and the coins sorted.
+// Responses with invalid amounts are rejected.
+func NormalizeMessageFunds() Option {
+	return func(w *Wasmer) {
+		w.normalizeMsgFunds = true
+	}
+}
+
+// CaptureBacktraces keeps the backtrace the vm reports with a contract error (e.g. a trap) in
+// the Backtrace of the returned types.VMError, for debugging. Backtraces differ between nodes, so
+// this is only for non-consensus uses like simulations. By default they are dropped, and only the
+// deterministic message remains.
+func CaptureBacktraces() Option {
+	return func(w *Wasmer) {
+		w.captureBacktraces = true
+	}
+}
+
+// AllowUint256Amounts makes the Wasmer accept coin amounts of up to 256 bits where it validates them
+// (sent funds and, with NormalizeMessageFunds, message funds), for tokens bridged from EVM chains.
+// By default amounts must fit in 128 bits, like cosmwasm-std's Uint128.
+func AllowUint256Amounts() Option {
+	return func(w *Wasmer) {
+		w.uint256Amounts = true
+	}
+}
+
+// DeleteGasRefund refunds perDelete gas for every storage delete of a contract call,
+// in total at most capPercent (0-100) percent of the gas used by the call.
+// The refund is deducted from the gas used reported for the call.
+func DeleteGasRefund(perDelete uint64, capPercent uint64) Option {
+	return func(w *Wasmer) {
+		if capPercent > 100 {
+			capPercent = 100
+		}
+		w.refundPerDelete = perDelete
+		w.refundCapPercent = capPercent
+	}
+}
+
+// MemoryBudget caps the memory of all instances running at the same time to total bytes,
+// reserving perInstance bytes for every contract call until it returns.
+// A call that does not fit waits for another one to finish, or fails once its context is done.
+//
+// Contract queries answered by calling back into the Wasmer must use the *Context methods
+// with the context passed to the ContextQuerier, so they share the reservation of the
+// querying call. Otherwise a call holding the last slot deadlocks on its own queries.
+func MemoryBudget(total uint64, perInstance uint64) Option {
+	return func(w *Wasmer) {
+		w.memory = newMemoryBudget(total, perInstance)
+	}
+}
+
+// CompileTimeout makes Create and StoreCode fail with a CompileTimeoutError when compiling
+// the code takes longer than timeout, protecting the node from uploads hitting a compiler edge case.
+// By default there is no limit, which fits trusted code.
+func CompileTimeout(timeout time.Duration) Option {
+	return func(w *Wasmer) {
+		w.compileTimeout = timeout
+	}
+}
+
+// MaxIterators limits the number of iterators one contract call may open to max.
+// Contracts cannot close iterators themselves, they are all closed when the call ends,
+// so this bounds the host resources a single call can hold on to. By default there is no limit.
+func MaxIterators(max uint64) Option {
+	return func(w *Wasmer) {
+		w.maxIterators = max
+	}
+}
@@ -0,0 +1,95 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// ExecRequest holds all arguments of one Execute call, see ExecuteParallel
+type ExecRequest struct {
+	Code     CodeID
+	Env      types.Env
+	Msg      []byte
+	Store    KVStore
+	GoAPI    GoAPI
+	Querier  Querier
+	GasMeter GasMeter
+	GasLimit uint64
+}
+
+// ExecResult holds the outcome of one Execute call, see ExecuteParallel
+type ExecResult struct {
+	Response *types.HandleResponse
+	GasUsed  uint64
+	Err      error
+}
+
+// ExecuteParallel runs independent Execute calls on a bounded pool of workers (see ParallelWorkers).
+// Each call runs in its own instance, results are returned in the order of the requests.
+// A failing call is reported in its ExecResult and does not affect the others.
+//
+// The calls must not share any state: every request needs its own Store, and the contracts must
+// not read what another one of the batch writes. This is not checked beyond rejecting a store
+// passed to more than one request, in which case nothing is executed and an error is returned.
+func (w *Wasmer) ExecuteParallel(reqs []ExecRequest) ([]ExecResult, error) {
+	return w.executeParallel(reqs, func(req ExecRequest) ExecResult {
+		resp, gasUsed, err := w.Execute(req.Code, req.Env, req.Msg, req.Store, req.GoAPI, req.Querier, req.GasMeter, req.GasLimit)
+		return ExecResult{Response: resp, GasUsed: gasUsed, Err: err}
+	})
+}
+
+func (w *Wasmer) executeParallel(reqs []ExecRequest, exec func(ExecRequest) ExecResult) ([]ExecResult, error) {
+	if err := checkDistinctStores(reqs); err != nil {
+		return nil, err
+	}
+
+	workers := w.parallelWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+
+	results := make([]ExecResult, len(reqs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = exec(reqs[idx])
+			}
+		}()
+	}
+	for idx := range reqs {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+	return results, nil
+}
+
+// checkDistinctStores ensures no store handle is used by two requests.
+// Stores that are not comparable (e.g. maps) cannot be checked and are skipped.
+func checkDistinctStores(reqs []ExecRequest) error {
+	seen := make(map[interface{}]int, len(reqs))
+	for i, req := range reqs {
+		if req.Store == nil {
+			return fmt.Errorf("request %d: Null/Empty argument: store", i)
+		}
+		if !reflect.TypeOf(req.Store).Comparable() {
+			continue
+		}
+		if j, ok := seen[req.Store]; ok {
+			return fmt.Errorf("requests %d and %d share the same store", j, i)
+		}
+		seen[req.Store] = i
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// mapStore is a minimal KVStore, enough for the fake executions below
+type mapStore struct {
+	data map[string][]byte
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{data: map[string][]byte{}}
+}
+
+func (s *mapStore) Get(key []byte) []byte                          { return s.data[string(key)] }
+func (s *mapStore) Set(key, value []byte)                          { s.data[string(key)] = value }
+func (s *mapStore) Delete(key []byte)                              { delete(s.data, string(key)) }
+func (s *mapStore) Iterator(start, end []byte) dbm.Iterator        { panic("not implemented") }
+func (s *mapStore) ReverseIterator(start, end []byte) dbm.Iterator { panic("not implemented") }
+
+// fakeExec stands in for the vm: it increments a counter in the store and logs the result
+func fakeExec(req ExecRequest) ExecResult {
+	if string(req.Msg) == "fail" {
+		return ExecResult{Err: fmt.Errorf("failed on %s", req.Env.Contract.Address)}
+	}
+	count := len(req.Store.Get([]byte("count")))
+	req.Store.Set([]byte("count"), make([]byte, count+1))
+	return ExecResult{
+		Response: &types.HandleResponse{
+			Log: []types.LogAttribute{{Key: string(req.Env.Contract.Address), Value: fmt.Sprintf("%s:%d", req.Msg, count+1)}},
+		},
+		GasUsed: uint64(len(req.Msg)),
+	}
+}
+
+func parallelRequests(n int) []ExecRequest {
+	reqs := make([]ExecRequest, n)
+	for i := range reqs {
+		reqs[i] = ExecRequest{
+			Env:   types.Env{Contract: types.ContractInfo{Address: types.HumanAddress(fmt.Sprintf("contract%d", i))}},
+			Msg:   []byte(fmt.Sprintf("msg%d", i)),
+			Store: newMapStore(),
+		}
+	}
+	reqs[3].Msg = []byte("fail")
+	return reqs
+}
+
+func TestExecuteParallel(t *testing.T) {
+	w := Wasmer{}
+	ParallelWorkers(3)(&w)
+
+	results, err := w.executeParallel(parallelRequests(10), fakeExec)
+	require.NoError(t, err)
+	require.Len(t, results, 10)
+	for i, res := range results {
+		if i == 3 {
+			require.Error(t, res.Err)
+			assert.Equal(t, "failed on contract3", res.Err.Error())
+			continue
+		}
+		require.NoError(t, res.Err)
+		// results come back in input order, and every call had its own store
+		addr := fmt.Sprintf("contract%d", i)
+		assert.Equal(t, []types.LogAttribute{{Key: addr, Value: fmt.Sprintf("msg%d:1", i)}}, res.Response.Log)
+	}
+
+	// the combined output does not depend on scheduling
+	for _, workers := range []int{1, 2, 7, 0} {
+		w := Wasmer{}
+		ParallelWorkers(workers)(&w)
+		again, err := w.executeParallel(parallelRequests(10), fakeExec)
+		require.NoError(t, err)
+		assert.Equal(t, results, again, "workers: %d", workers)
+	}
+}
+
+func TestExecuteParallelRejectsSharedStore(t *testing.T) {
+	w := Wasmer{}
+	reqs := parallelRequests(4)
+	reqs[2].Store = reqs[0].Store
+	_, err := w.executeParallel(reqs, fakeExec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requests 0 and 2 share the same store")
+	// nothing was executed
+	assert.Nil(t, reqs[0].Store.Get([]byte("count")))
+
+	reqs[2].Store = nil
+	_, err = w.executeParallel(reqs, fakeExec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request 2: Null/Empty argument: store")
+
+	results, err := w.executeParallel(nil, fakeExec)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
@@ -0,0 +1,60 @@
+package cosmwasm
+
+import (
+	"encoding/json"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+type cachedQuery struct {
+	res     []byte
+	err     error
+	gasUsed uint64
+}
+
+// queryCache is a Querier that answers identical queries made during one vm call from a cache.
+// It lives only as long as the call, so it never outlives the state it was filled from.
+type queryCache struct {
+	Querier
+	// if set, a cached response costs the same gas as the original query
+	chargeRepeated bool
+	cache          map[string]cachedQuery
+	// gas charged for cached responses, which the wrapped querier never saw
+	extraGas uint64
+}
+
+var _ Querier = (*queryCache)(nil)
+
+func newQueryCache(querier Querier, chargeRepeated bool) *queryCache {
+	return &queryCache{
+		Querier:        querier,
+		chargeRepeated: chargeRepeated,
+		cache:          make(map[string]cachedQuery),
+	}
+}
+
+func (q *queryCache) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	bz, err := json.Marshal(request)
+	if err != nil {
+		return q.Querier.Query(request, gasLimit)
+	}
+	key := string(bz)
+	if cached, ok := q.cache[key]; ok {
+		if q.chargeRepeated {
+			q.extraGas += cached.gasUsed
+		}
+		return cached.res, cached.err
+	}
+
+	gasBefore := q.Querier.GasConsumed()
+	res, err := q.Querier.Query(request, gasLimit)
+	gasUsed := q.Querier.GasConsumed() - gasBefore
+	q.cache[key] = cachedQuery{res: res, err: err, gasUsed: gasUsed}
+	return res, err
+}
+
+// GasConsumed includes the gas charged for cached responses. The vm measures query cost as
+// the difference of GasConsumed before and after a query, so this is what it charges.
+func (q *queryCache) GasConsumed() uint64 {
+	return q.Querier.GasConsumed() + q.extraGas
+}
@@ -0,0 +1,71 @@
+package cosmwasm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// countingQuerier charges 100 gas per query and counts how often it was asked
+type countingQuerier struct {
+	calls int
+	gas   uint64
+}
+
+func (q *countingQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	q.calls++
+	q.gas += 100
+	return []byte(`{"amount":[]}`), nil
+}
+
+func (q *countingQuerier) GasConsumed() uint64 {
+	return q.gas
+}
+
+// runQueries issues queries the way the vm does, returning the total gas charged
+func runQueries(t *testing.T, querier Querier, requests ...types.QueryRequest) uint64 {
+	var total uint64
+	for _, req := range requests {
+		bz, err := json.Marshal(req)
+		require.NoError(t, err)
+		before := querier.GasConsumed()
+		res := types.RustQuery(querier, bz, 1000)
+		require.NotNil(t, res.Ok)
+		total += querier.GasConsumed() - before
+	}
+	return total
+}
+
+func TestQueryCache(t *testing.T) {
+	foo := types.QueryRequest{Bank: &types.BankQuery{AllBalances: &types.AllBalancesQuery{Address: "foo"}}}
+	bar := types.QueryRequest{Bank: &types.BankQuery{AllBalances: &types.AllBalancesQuery{Address: "bar"}}}
+
+	// without cache, every query hits the querier
+	inner := &countingQuerier{}
+	w := Wasmer{}
+	gas := runQueries(t, w.wrapQuerier(nil, inner), foo, foo, bar, foo)
+	assert.Equal(t, 4, inner.calls)
+	assert.Equal(t, uint64(400), gas)
+
+	// with cache, repeated queries are free
+	inner = &countingQuerier{}
+	CacheQueries(false)(&w)
+	gas = runQueries(t, w.wrapQuerier(nil, inner), foo, foo, bar, foo)
+	assert.Equal(t, 2, inner.calls)
+	assert.Equal(t, uint64(200), gas)
+
+	// or charged like the original
+	inner = &countingQuerier{}
+	CacheQueries(true)(&w)
+	gas = runQueries(t, w.wrapQuerier(nil, inner), foo, foo, bar, foo)
+	assert.Equal(t, 2, inner.calls)
+	assert.Equal(t, uint64(400), gas)
+
+	// every call gets a fresh cache
+	runQueries(t, w.wrapQuerier(nil, inner), foo)
+	assert.Equal(t, 3, inner.calls)
+}
@@ -0,0 +1,55 @@
+package cosmwasm
+
+import (
+	"context"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// queryFailures is a Querier that remembers the first query of a vm call that failed.
+// The contract only sees an error result, so this is the only place the host can still tell
+// a query it could not answer (a SystemError) from a query answered with an error.
+type queryFailures struct {
+	Querier
+	failure *types.QueryFailedError
+}
+
+var _ ContextQuerier = (*queryFailures)(nil)
+
+func (q *queryFailures) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	return q.QueryContext(context.Background(), request, gasLimit)
+}
+
+// QueryContext passes ctx on to the wrapped querier, if it is a ContextQuerier itself.
+// This keeps the wrapper transparent to withContext, which binds the ctx of the call.
+func (q *queryFailures) QueryContext(ctx context.Context, request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	res, err := withContext(ctx, q.Querier).Query(request, gasLimit)
+	if err != nil && q.failure == nil {
+		q.failure = &types.QueryFailedError{Request: request}
+		if sys := types.ToSystemError(err); sys != nil {
+			q.failure.System = sys
+		} else {
+			q.failure.Contract = types.ToStdError(err)
+		}
+	}
+	return res, err
+}
+
+// trackQueryFailures wraps the querier of a vm call, if the Wasmer reports query failures
+func (w *Wasmer) trackQueryFailures(querier Querier) (Querier, *queryFailures) {
+	if !w.reportQueryFailures || querier == nil {
+		return querier, nil
+	}
+	failures := &queryFailures{Querier: querier}
+	return failures, failures
+}
+
+// wrap returns err as a QueryFailedError if a query failed during the call
+func (q *queryFailures) wrap(err error) error {
+	if err == nil || q == nil || q.failure == nil {
+		return err
+	}
+	failure := *q.failure
+	failure.Err = err
+	return failure
+}
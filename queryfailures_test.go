@@ -0,0 +1,105 @@
+package cosmwasm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// failingQuerier answers queries of contract "ok", and fails all other wasm queries with errs[addr]
+type failingQuerier struct {
+	errs map[string]error
+}
+
+func (q failingQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	addr := request.Wasm.Smart.ContractAddr
+	if addr == "ok" {
+		return []byte(`{}`), nil
+	}
+	return nil, q.errs[addr]
+}
+
+func (q failingQuerier) GasConsumed() uint64 {
+	return 0
+}
+
+func smartQuery(addr string) types.QueryRequest {
+	return types.QueryRequest{Wasm: &types.WasmQuery{Smart: &types.SmartQuery{ContractAddr: addr, Msg: []byte(`{}`)}}}
+}
+
+func TestQueryFailures(t *testing.T) {
+	inner := failingQuerier{errs: map[string]error{
+		"missing": types.NoSuchContract{Addr: "missing"},
+		"broken":  types.GenericErr{Msg: "query logic failed"},
+	}}
+	callErr := fmt.Errorf("contract failed")
+
+	cases := map[string]struct {
+		queries  []string
+		failed   string
+		system   *types.SystemError
+		contract *types.StdError
+	}{
+		"no such contract": {
+			queries: []string{"ok", "missing"},
+			failed:  "missing",
+			system:  &types.SystemError{NoSuchContract: &types.NoSuchContract{Addr: "missing"}},
+		},
+		"contract errored": {
+			queries:  []string{"broken"},
+			failed:   "broken",
+			contract: &types.StdError{GenericErr: &types.GenericErr{Msg: "query logic failed"}},
+		},
+		"first failure wins": {
+			queries:  []string{"broken", "missing"},
+			failed:   "broken",
+			contract: &types.StdError{GenericErr: &types.GenericErr{Msg: "query logic failed"}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := Wasmer{}
+			ReportQueryFailures()(&w)
+			querier, failures := w.trackQueryFailures(inner)
+			for _, addr := range tc.queries {
+				_, _ = querier.Query(smartQuery(addr), 1000)
+			}
+
+			err := failures.wrap(callErr)
+			var failed types.QueryFailedError
+			require.True(t, errors.As(err, &failed))
+			assert.Equal(t, tc.system, failed.System)
+			assert.Equal(t, tc.contract, failed.Contract)
+			assert.Equal(t, smartQuery(tc.failed), failed.Request)
+			assert.True(t, errors.Is(err, callErr))
+		})
+	}
+}
+
+func TestQueryFailuresUnchanged(t *testing.T) {
+	callErr := fmt.Errorf("contract failed")
+	inner := failingQuerier{errs: map[string]error{"missing": types.NoSuchContract{Addr: "missing"}}}
+
+	// successful queries leave the error alone
+	w := Wasmer{}
+	ReportQueryFailures()(&w)
+	querier, failures := w.trackQueryFailures(inner)
+	_, err := querier.Query(smartQuery("ok"), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, callErr, failures.wrap(callErr))
+
+	// a successful call stays successful
+	_, _ = querier.Query(smartQuery("missing"), 1000)
+	assert.NoError(t, failures.wrap(nil))
+
+	// and nothing is tracked by default
+	w = Wasmer{}
+	querier, failures = w.trackQueryFailures(inner)
+	assert.Equal(t, inner, querier)
+	assert.Equal(t, callErr, failures.wrap(callErr))
+}
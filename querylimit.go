@@ -0,0 +1,27 @@
+package cosmwasm
+
+import (
+	"fmt"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// queryResponseLimit is a Querier that rejects responses larger than maxSize bytes, before
+// they are copied into the vm. See MaxQueryResponseSize.
+type queryResponseLimit struct {
+	Querier
+	maxSize int
+}
+
+var _ Querier = queryResponseLimit{}
+
+func (q queryResponseLimit) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	res, err := q.Querier.Query(request, gasLimit)
+	if err == nil && len(res) > q.maxSize {
+		return nil, types.InvalidResponse{
+			Err:      fmt.Sprintf("query response of %d bytes exceeds the limit of %d bytes", len(res), q.maxSize),
+			Response: []byte{},
+		}
+	}
+	return res, err
+}
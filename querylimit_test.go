@@ -0,0 +1,50 @@
+package cosmwasm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// sizedQuerier answers every query with size bytes
+type sizedQuerier struct {
+	size int
+}
+
+func (q sizedQuerier) Query(request types.QueryRequest, gasLimit uint64) ([]byte, error) {
+	return bytes.Repeat([]byte("a"), q.size), nil
+}
+
+func (q sizedQuerier) GasConsumed() uint64 {
+	return 0
+}
+
+func TestMaxQueryResponseSize(t *testing.T) {
+	request, err := json.Marshal(smartQuery("big"))
+	require.NoError(t, err)
+
+	// no limit by default
+	w := Wasmer{}
+	res := types.RustQuery(w.wrapQuerier(context.Background(), sizedQuerier{size: 1 << 20}), request, 1000)
+	require.NotNil(t, res.Ok)
+	assert.Len(t, res.Ok.Ok, 1<<20)
+
+	MaxQueryResponseSize(1024)(&w)
+	res = types.RustQuery(w.wrapQuerier(context.Background(), sizedQuerier{size: 1024}), request, 1000)
+	require.NotNil(t, res.Ok)
+	assert.Len(t, res.Ok.Ok, 1024)
+
+	// oversized responses become a system error for the contract
+	res = types.RustQuery(w.wrapQuerier(context.Background(), sizedQuerier{size: 1025}), request, 1000)
+	assert.Nil(t, res.Ok)
+	require.NotNil(t, res.Err)
+	require.NotNil(t, res.Err.InvalidResponse)
+	assert.Equal(t, "query response of 1025 bytes exceeds the limit of 1024 bytes", res.Err.InvalidResponse.Err)
+	assert.Empty(t, res.Err.InvalidResponse.Response)
+}
@@ -0,0 +1,37 @@
+package cosmwasm
+
+// deleteCounter is a KVStore counting the deletes done through it, to compute gas refunds
+type deleteCounter struct {
+	KVStore
+	deletes uint64
+}
+
+func (s *deleteCounter) Delete(key []byte) {
+	s.KVStore.Delete(key)
+	s.deletes++
+}
+
+// trackDeletes wraps store to count deletes if the Wasmer refunds gas for them.
+// Otherwise it returns the store unchanged and a nil counter.
+func (w *Wasmer) trackDeletes(store KVStore) (KVStore, *deleteCounter) {
+	if w.refundPerDelete == 0 || store == nil {
+		return store, nil
+	}
+	counter := &deleteCounter{KVStore: store}
+	return counter, counter
+}
+
+// applyRefund reduces gasUsed by the refund earned for deletes, capped at refundCapPercent of gasUsed.
+// It must only be applied to successful calls, as the deletes of a failed one are reverted.
+// The refund is computed with integers only, so it is deterministic.
+func (w *Wasmer) applyRefund(gasUsed uint64, counter *deleteCounter) uint64 {
+	if counter == nil || counter.deletes == 0 {
+		return gasUsed
+	}
+	maxRefund := gasUsed / 100 * w.refundCapPercent
+	refund := maxRefund
+	if counter.deletes < maxRefund/w.refundPerDelete {
+		refund = counter.deletes * w.refundPerDelete
+	}
+	return gasUsed - refund
+}
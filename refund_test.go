@@ -0,0 +1,42 @@
+package cosmwasm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteGasRefund(t *testing.T) {
+	// no refunds by default
+	w := Wasmer{}
+	store, counter := w.trackDeletes(newMapStore())
+	require.Nil(t, counter)
+	store.Delete([]byte("foo"))
+	assert.Equal(t, uint64(50000), w.applyRefund(50000, counter))
+
+	DeleteGasRefund(100, 20)(&w)
+	inner := newMapStore()
+	inner.Set([]byte("foo"), []byte("bar"))
+	store, counter = w.trackDeletes(inner)
+	require.NotNil(t, counter)
+
+	// a few deletes are refunded in full
+	store.Delete([]byte("foo"))
+	store.Delete([]byte("missing"))
+	assert.Nil(t, inner.Get([]byte("foo")))
+	assert.Equal(t, uint64(2), counter.deletes)
+	assert.Equal(t, uint64(49800), w.applyRefund(50000, counter))
+
+	// heavy deletion is capped at 20% of the gas used
+	for i := 0; i < 1000; i++ {
+		store.Delete([]byte(fmt.Sprintf("key%d", i)))
+	}
+	assert.Equal(t, uint64(40000), w.applyRefund(50000, counter))
+	assert.Equal(t, uint64(0), w.applyRefund(0, counter))
+
+	// the cap cannot exceed the gas used
+	DeleteGasRefund(100, 250)(&w)
+	assert.Equal(t, uint64(0), w.applyRefund(50000, counter))
+}
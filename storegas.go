@@ -0,0 +1,68 @@
+package cosmwasm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	// DefaultStoreGasPerByte is charged per byte of (decompressed) wasm code, like the compile cost of wasmd
+	DefaultStoreGasPerByte uint64 = 2
+	// DefaultStoreGasPerFunction is charged per function defined in the code
+	DefaultStoreGasPerFunction uint64 = 100
+)
+
+// MaxDecompressedWasmSize is the largest code EstimateStoreGas decompresses, to protect against gzip bombs
+const MaxDecompressedWasmSize = 10 * 1024 * 1024
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompressWasm returns code, gunzipped if it is gzip compressed
+func decompressWasm(code []byte) ([]byte, error) {
+	if !bytes.HasPrefix(code, gzipMagic) {
+		return code, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(code))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWasm, err)
+	}
+	defer zr.Close()
+	wasm, err := ioutil.ReadAll(io.LimitReader(zr, MaxDecompressedWasmSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWasm, err)
+	}
+	if len(wasm) > MaxDecompressedWasmSize {
+		return nil, fmt.Errorf("%w: decompressed code exceeds %d bytes", ErrInvalidWasm, MaxDecompressedWasmSize)
+	}
+	return wasm, nil
+}
+
+// EstimateStoreGas returns the gas StoreCode reports for storing code, without storing it.
+// The code may be gzip compressed, the charge is always based on the decompressed size
+// (see StoreGasCost) and the number of functions the code defines.
+func (w *Wasmer) EstimateStoreGas(code []byte) (uint64, error) {
+	wasm, err := decompressWasm(code)
+	if err != nil {
+		return 0, err
+	}
+	return w.storeGas(wasm)
+}
+
+// storeGas computes the charge for storing the (uncompressed) wasm code
+func (w *Wasmer) storeGas(wasm []byte) (uint64, error) {
+	if err := checkWasmHeader(wasm); err != nil {
+		return 0, err
+	}
+	module, err := parseWasm(wasm)
+	if err != nil {
+		return 0, err
+	}
+	perByte, perFunction := DefaultStoreGasPerByte, DefaultStoreGasPerFunction
+	if w.storeGasCost != nil {
+		perByte, perFunction = w.storeGasCost.perByte, w.storeGasCost.perFunction
+	}
+	return perByte*uint64(len(wasm)) + perFunction*uint64(module.Functions), nil
+}
@@ -0,0 +1,92 @@
+package cosmwasm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipCode(t *testing.T, code []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(code)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestEstimateStoreGas(t *testing.T) {
+	code := readTestdata(t, "hackatom.wasm")
+	module, err := parseWasm(code)
+	require.NoError(t, err)
+
+	w := Wasmer{}
+	gas, err := w.EstimateStoreGas(code)
+	require.NoError(t, err)
+	assert.Equal(t, 2*uint64(len(code))+100*uint64(module.Functions), gas)
+
+	// compressed uploads are charged by their decompressed size
+	zipped := gzipCode(t, code)
+	require.Less(t, len(zipped), len(code))
+	zippedGas, err := w.EstimateStoreGas(zipped)
+	require.NoError(t, err)
+	assert.Equal(t, gas, zippedGas)
+
+	StoreGasCost(1, 0)(&w)
+	gas, err = w.EstimateStoreGas(code)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(code)), gas)
+
+	_, err = w.EstimateStoreGas([]byte("not wasm"))
+	assert.True(t, errors.Is(err, ErrInvalidWasm))
+	_, err = w.EstimateStoreGas(gzipCode(t, []byte("not wasm")))
+	assert.True(t, errors.Is(err, ErrInvalidWasm))
+	_, err = w.EstimateStoreGas(gzipMagic)
+	assert.True(t, errors.Is(err, ErrInvalidWasm))
+}
+
+func TestStoreCodeGasMatchesEstimate(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "go-cosmwasm")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	w, err := NewWasmer(tmpdir, "staking", 3, StoreGasCost(3, 250))
+	require.NoError(t, err)
+	defer w.Cleanup()
+
+	for _, name := range []string{"hackatom.wasm", "queue.wasm", "reflect.wasm"} {
+		code := readTestdata(t, name)
+		estimate, err := w.EstimateStoreGas(code)
+		require.NoError(t, err)
+		res, err := w.StoreCode(code)
+		require.NoError(t, err)
+		assert.Equal(t, estimate, res.Gas, name)
+	}
+}
+
+func TestStoreCodeGasMatchesEstimateGzipped(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "go-cosmwasm")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+	w, err := NewWasmer(tmpdir, "staking", 3)
+	require.NoError(t, err)
+	defer w.Cleanup()
+
+	code := readTestdata(t, "hackatom.wasm")
+	zipped := gzipCode(t, code)
+	estimate, err := w.EstimateStoreGas(zipped)
+	require.NoError(t, err)
+	res, err := w.StoreCode(zipped)
+	require.NoError(t, err)
+	assert.Equal(t, estimate, res.Gas)
+
+	// it is the same as storing the plain code
+	plain, err := w.StoreCode(code)
+	require.NoError(t, err)
+	assert.Equal(t, plain, res)
+}
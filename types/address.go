@@ -0,0 +1,32 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// ContractAddress derives the canonical address of the instanceID-th contract instance (counted over
+// all codes), which was instantiated from code codeID. This is the derivation of the wasmd keeper:
+// the first 20 bytes of sha256('C' || uvarint(codeID<<32 + instanceID)).
+func ContractAddress(codeID uint64, instanceID uint64) CanonicalAddress {
+	contractID := codeID<<32 + instanceID
+	addr := make([]byte, 20)
+	addr[0] = 'C'
+	binary.PutUvarint(addr[1:], contractID)
+	hash := sha256.Sum256(addr)
+	return hash[:20]
+}
+
+// PredictInstantiateAddresses returns the addresses of the contracts the instantiate messages in msgs
+// will create, in order, skipping all other messages. nextInstanceID is the instance id the keeper
+// will assign to the first of them; every instantiation takes the next one.
+func PredictInstantiateAddresses(msgs []CosmosMsg, nextInstanceID uint64) []CanonicalAddress {
+	var addrs []CanonicalAddress
+	for _, msg := range msgs {
+		if msg.Wasm != nil && msg.Wasm.Instantiate != nil {
+			addrs = append(addrs, ContractAddress(msg.Wasm.Instantiate.CodeID, nextInstanceID))
+			nextInstanceID++
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractAddress(t *testing.T) {
+	// the first contract on a fresh chain (cosmos18vd8fpwxzck93qlwghaj6arh4p7c5n89uzcee5)
+	assert.Equal(t, "3b1a7485c6162c5883ee45fb2d7477a87d8a4ce5", hex.EncodeToString(ContractAddress(1, 1)))
+	assert.NotEqual(t, ContractAddress(1, 2), ContractAddress(2, 1))
+}
+
+func TestPredictInstantiateAddresses(t *testing.T) {
+	msgs := []CosmosMsg{
+		{Wasm: &WasmMsg{Instantiate: &InstantiateMsg{CodeID: 7, Msg: []byte(`{}`), Label: "child 1"}}},
+		{Bank: &BankMsg{Send: &SendMsg{FromAddress: "factory", ToAddress: "bob"}}},
+		{Wasm: &WasmMsg{Execute: &ExecuteMsg{ContractAddr: "other", Msg: []byte(`{}`)}}},
+		{Wasm: &WasmMsg{Instantiate: &InstantiateMsg{CodeID: 7, Msg: []byte(`{}`), Label: "child 2"}}},
+		{Wasm: &WasmMsg{Instantiate: &InstantiateMsg{CodeID: 3, Msg: []byte(`{}`), Label: "other child"}}},
+	}
+	addrs := PredictInstantiateAddresses(msgs, 5)
+	assert.Len(t, addrs, 3)
+	assert.Equal(t, "3b5658d02dc4d56eebef8109442d4f776a79c776", hex.EncodeToString(addrs[0]))
+	assert.Equal(t, "0e2d387566a63244f34262f6289067ef39b10dab", hex.EncodeToString(addrs[1]))
+	assert.Equal(t, "30324da8f22990c784b10b9cbd03064956d624a2", hex.EncodeToString(addrs[2]))
+	assert.Equal(t, ContractAddress(3, 7), addrs[2])
+
+	assert.Empty(t, PredictInstantiateAddresses(msgs[1:3], 5))
+}
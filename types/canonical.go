@@ -0,0 +1,19 @@
+package types
+
+// CosmosResponse is a successful response of a contract call: InitResponse, HandleResponse or MigrateResponse
+type CosmosResponse interface {
+	// messages returns the messages the contract wants to dispatch
+	messages() []CosmosMsg
+}
+
+func (r InitResponse) messages() []CosmosMsg    { return r.Messages }
+func (r HandleResponse) messages() []CosmosMsg  { return r.Messages }
+func (r MigrateResponse) messages() []CosmosMsg { return r.Messages }
+
+// CanonicalEncode returns a deterministic encoding of resp, suitable for hashing it (e.g. to store it in state).
+// This is the CBOR encoding of MarshalCBOR: all objects, including the ones inside custom messages,
+// have their keys sorted, and the encoding of every value is fixed by RFC 7049 instead of depending
+// on the Go version. The raw response is not part of it.
+func CanonicalEncode(resp CosmosResponse) ([]byte, error) {
+	return MarshalCBOR(resp)
+}
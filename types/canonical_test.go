@@ -0,0 +1,41 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalEncode(t *testing.T) {
+	resp := HandleResponse{
+		Messages: []CosmosMsg{
+			{Bank: &BankMsg{Send: &SendMsg{FromAddress: "a", ToAddress: "b", Amount: Coins{{Denom: "uscrt", Amount: "5"}}}}},
+			{Custom: json.RawMessage(`{"z":1,"a":[true,null]}`)},
+		},
+		Data:        []byte("hi"),
+		Log:         []LogAttribute{{Key: "action", Value: "x"}},
+		RawResponse: []byte("ignored"),
+	}
+	bz, err := CanonicalEncode(resp)
+	require.NoError(t, err)
+	assert.Equal(t, "a36464617461426869636c6f6781a2636b657966616374696f6e6576616c75656178686d6573736167657382a16462616e6ba16473656e64a366616d6f756e7481a266616d6f756e7461356564656e6f6d6575736372746c66726f6d5f6164647265737361616a746f5f616464726573736162a166637573746f6da2616182f5f6617a01", hex.EncodeToString(bz))
+
+	// the order of keys in custom messages does not matter
+	reordered := resp
+	reordered.Messages = []CosmosMsg{resp.Messages[0], {Custom: json.RawMessage(`{ "a": [true, null], "z": 1 }`)}}
+	reordered.RawResponse = nil
+	bz2, err := CanonicalEncode(reordered)
+	require.NoError(t, err)
+	assert.Equal(t, bz, bz2)
+
+	init, err := CanonicalEncode(InitResponse{Messages: []CosmosMsg{}, Log: []LogAttribute{}, Admin: "admin"})
+	require.NoError(t, err)
+	assert.Equal(t, "a46561646d696e6561646d696e6464617461f6636c6f6780686d6573736167657380", hex.EncodeToString(init))
+
+	migrate, err := CanonicalEncode(&MigrateResponse{})
+	require.NoError(t, err)
+	assert.Equal(t, "a36464617461f6636c6f67f6686d65737361676573f6", hex.EncodeToString(migrate))
+}
@@ -0,0 +1,430 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MarshalCBOR encodes v (e.g. an Env) as CBOR (RFC 7049), a more compact binary alternative to json.
+// The encoding follows the json representation of v: the same field names are used, and values
+// with their own json encoding (e.g. Coins or custom messages) are encoded like their json.
+// Byte slices are CBOR byte strings instead of base64 text, and decode back into the same
+// byte slices, so a CBOR document can always be converted to the json one.
+// Map keys and struct fields are sorted, so the encoding is deterministic.
+func MarshalCBOR(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeCBORValue(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalCBOR decodes CBOR produced by MarshalCBOR into v
+func UnmarshalCBOR(data []byte, v interface{}) error {
+	d := cborDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return err
+	}
+	if d.pos != len(data) {
+		return fmt.Errorf("cbor: %d trailing bytes", len(data)-d.pos)
+	}
+	bz, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, v)
+}
+
+// cbor major types
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborSimple = 7
+)
+
+// maxCBORDepth protects against stack exhaustion on malicious input
+const maxCBORDepth = 128
+
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		_ = binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		_ = binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major | 27)
+		_ = binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// encodeCBORValue encodes v following the rules encoding/json uses for it
+func encodeCBORValue(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteByte(cborSimple<<5 | 22)
+		return nil
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		buf.WriteByte(cborSimple<<5 | 22)
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		return encodeCBORJSON(buf, v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return encodeCBORValue(buf, v.Elem())
+	case reflect.Bool:
+		return encodeCBOR(buf, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := v.Int(); i < 0 {
+			writeCBORHead(buf, cborNegint, uint64(-(i + 1)))
+		} else {
+			writeCBORHead(buf, cborUint, uint64(i))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeCBORHead(buf, cborUint, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		buf.WriteByte(cborSimple<<5 | 27)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v.Float()))
+	case reflect.String:
+		writeCBORHead(buf, cborText, uint64(v.Len()))
+		buf.WriteString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteByte(cborSimple<<5 | 22)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			writeCBORHead(buf, cborBytes, uint64(v.Len()))
+			buf.Write(v.Bytes())
+			return nil
+		}
+		return encodeCBORArray(buf, v)
+	case reflect.Array:
+		return encodeCBORArray(buf, v)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("cbor: unsupported map key type %s", v.Type().Key())
+		}
+		if v.IsNil() {
+			buf.WriteByte(cborSimple<<5 | 22)
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+			values[k.String()] = v.MapIndex(k)
+		}
+		return encodeCBORMap(buf, keys, values)
+	case reflect.Struct:
+		values := make(map[string]reflect.Value)
+		collectCBORFields(v, values)
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		return encodeCBORMap(buf, keys, values)
+	default:
+		return fmt.Errorf("cbor: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// encodeCBORJSON encodes a value with its own json encoding like that json
+func encodeCBORJSON(buf *bytes.Buffer, v interface{}) error {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	return encodeCBOR(buf, value)
+}
+
+func encodeCBORArray(buf *bytes.Buffer, v reflect.Value) error {
+	writeCBORHead(buf, cborArray, uint64(v.Len()))
+	for i := 0; i < v.Len(); i++ {
+		if err := encodeCBORValue(buf, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeCBORMap(buf *bytes.Buffer, keys []string, values map[string]reflect.Value) error {
+	sort.Strings(keys)
+	writeCBORHead(buf, cborMap, uint64(len(keys)))
+	for _, k := range keys {
+		writeCBORHead(buf, cborText, uint64(len(k)))
+		buf.WriteString(k)
+		if err := encodeCBORValue(buf, values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectCBORFields adds the fields of struct v that encoding/json would encode to values,
+// by their json name. Embedded structs without a name add their fields.
+func collectCBORFields(v reflect.Value, values map[string]reflect.Value) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+		value := v.Field(i)
+		if field.Anonymous && name == "" {
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				collectCBORFields(value, values)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyJSONValue(value) {
+			continue
+		}
+		values[name] = value
+	}
+}
+
+// isEmptyJSONValue reports whether json omits v from a field tagged omitempty
+func isEmptyJSONValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+func encodeCBOR(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(cborSimple<<5 | 22)
+	case bool:
+		if v {
+			buf.WriteByte(cborSimple<<5 | 21)
+		} else {
+			buf.WriteByte(cborSimple<<5 | 20)
+		}
+	case json.Number:
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			writeCBORHead(buf, cborUint, u)
+		} else if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			writeCBORHead(buf, cborNegint, uint64(-(i + 1)))
+		} else {
+			f, err := v.Float64()
+			if err != nil {
+				return err
+			}
+			buf.WriteByte(cborSimple<<5 | 27)
+			_ = binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+		}
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			if err := encodeCBOR(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeCBORHead(buf, cborMap, uint64(len(v)))
+		for _, k := range keys {
+			writeCBORHead(buf, cborText, uint64(len(k)))
+			buf.WriteString(k)
+			if err := encodeCBOR(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", value)
+	}
+	return nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, fmt.Errorf("cbor: unexpected end of input")
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+func (d *cborDecoder) head() (byte, byte, uint64, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info := b[0]>>5, b[0]&31
+	var size uint64
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, 0, fmt.Errorf("cbor: unsupported additional info %d", info)
+	}
+	bz, err := d.next(size)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var n uint64
+	for _, x := range bz {
+		n = n<<8 | uint64(x)
+	}
+	return major, info, n, nil
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, fmt.Errorf("cbor: nested too deeply")
+	}
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case cborNegint:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: negative integer out of range")
+		}
+		return json.Number(strconv.FormatInt(-int64(n)-1, 10)), nil
+	case cborBytes:
+		bz, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		// json encodes it as base64, which decodes into the original []byte field
+		return append([]byte{}, bz...), nil
+	case cborText:
+		bz, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(bz), nil
+	case cborArray:
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("cbor: unexpected end of input")
+		}
+		res := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			item, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, item)
+		}
+		return res, nil
+	case cborMap:
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, fmt.Errorf("cbor: unexpected end of input")
+		}
+		res := make(map[string]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("cbor: map key must be text")
+			}
+			res[k], err = d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case cborSimple:
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		case 27:
+			return math.Float64frombits(n), nil
+		}
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+	default:
+		return nil, fmt.Errorf("cbor: unsupported major type %d", major)
+	}
+}
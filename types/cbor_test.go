@@ -0,0 +1,89 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvCBORRoundTrip(t *testing.T) {
+	index := uint32(2)
+	env := Env{
+		Block: BlockInfo{
+			Height:  123456789,
+			Time:    1578939743,
+			ChainID: "secret-2",
+			Random:  make([]byte, RandomLength),
+		},
+		Message: MessageInfo{
+			Sender:    "secret1sender",
+			SentFunds: Coins{{Denom: "uscrt", Amount: "12345"}},
+			MsgIndex:  &index,
+		},
+		Contract: ContractInfo{Address: "secret1contract", Label: "label ✓"},
+		Key:      "contract-key",
+	}
+
+	bz, err := MarshalCBOR(env)
+	require.NoError(t, err)
+	js, err := json.Marshal(env)
+	require.NoError(t, err)
+	assert.Less(t, len(bz), len(js))
+
+	// decoding both gives the same Env
+	var fromCBOR, fromJSON Env
+	require.NoError(t, UnmarshalCBOR(bz, &fromCBOR))
+	require.NoError(t, json.Unmarshal(js, &fromJSON))
+	assert.Equal(t, fromJSON, fromCBOR)
+	assert.Equal(t, env, fromCBOR)
+
+	// and the encoding is deterministic
+	again, err := MarshalCBOR(env)
+	require.NoError(t, err)
+	assert.Equal(t, bz, again)
+}
+
+func TestHandleResponseCBORRoundTrip(t *testing.T) {
+	resp := HandleResponse{
+		Messages: []CosmosMsg{{Bank: &BankMsg{Send: &SendMsg{FromAddress: "a", ToAddress: "b", Amount: Coins{{Denom: "uscrt", Amount: "1"}}}}}},
+		Data:     []byte{0, 1, 2},
+		Log:      []LogAttribute{{Key: "action", Value: "send"}},
+	}
+	bz, err := MarshalCBOR(resp)
+	require.NoError(t, err)
+	var recover HandleResponse
+	require.NoError(t, UnmarshalCBOR(bz, &recover))
+	assert.Equal(t, resp, recover)
+}
+
+func TestCBORKnownEncoding(t *testing.T) {
+	bz, err := MarshalCBOR(map[string]interface{}{"b": []int{1, -1, 1000}, "a": true, "c": nil})
+	require.NoError(t, err)
+	// {"a": true, "b": [1, -1, 1000], "c": null}
+	assert.Equal(t, []byte{0xa3, 0x61, 'a', 0xf5, 0x61, 'b', 0x83, 0x01, 0x20, 0x19, 0x03, 0xe8, 0x61, 'c', 0xf6}, bz)
+
+	// binary fields are byte strings, not base64 text
+	bz, err = MarshalCBOR(LogAttribute{Key: "k", Value: "v"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xa2, 0x63, 'k', 'e', 'y', 0x61, 'k', 0x65, 'v', 'a', 'l', 'u', 'e', 0x61, 'v'}, bz)
+	bz, err = MarshalCBOR(InitResponse{Messages: []CosmosMsg{}, Log: []LogAttribute{}, Data: []byte{1, 2, 3}})
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), "\x64data\x43\x01\x02\x03")
+	var decoded InitResponse
+	require.NoError(t, UnmarshalCBOR(bz, &decoded))
+	assert.Equal(t, []byte{1, 2, 3}, []byte(decoded.Data))
+}
+
+func TestCBORRejectsInvalid(t *testing.T) {
+	var env Env
+	// truncated
+	assert.Error(t, UnmarshalCBOR([]byte{0xa3, 0x61}, &env))
+	// trailing data
+	assert.Error(t, UnmarshalCBOR([]byte{0xa0, 0x00}, &env))
+	// huge length prefix
+	assert.Error(t, UnmarshalCBOR([]byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, &env))
+	// non text map key
+	assert.Error(t, UnmarshalCBOR([]byte{0xa1, 0x01, 0x01}, &env))
+}
@@ -1,5 +1,12 @@
 package types
 
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
 //---------- Env ---------
 
 // Env defines the state of the blockchain environment this contract is
@@ -16,12 +23,60 @@ type Env struct {
 
 type ContractKey string
 
+// ValidateKey ensures the env carries the expected contract key, e.g. the one returned from
+// instantiating the contract, so a key tampered with between init and handle is caught.
+// An empty expected key is rejected, as it would only match an env without a key.
+func (e Env) ValidateKey(expected ContractKey) error {
+	if expected == "" {
+		return fmt.Errorf("expected contract key is empty")
+	}
+	if subtle.ConstantTimeCompare([]byte(e.Key), []byte(expected)) != 1 {
+		return fmt.Errorf("contract key does not match the expected key")
+	}
+	return nil
+}
+
+// BlockInfo is passed to contracts as json. Height and Time stay json numbers, as the contracts
+// expect, and are decoded exactly into the uint64 fields, even above 2^53. Code decoding them
+// without a Go type (into interface{}) must use json.Decoder.UseNumber to not round them to float64.
 type BlockInfo struct {
 	// block height this transaction is executed
 	Height uint64 `json:"height"`
 	// time in seconds since unix epoch - since cosmwasm 0.3
 	Time    uint64 `json:"time"`
 	ChainID string `json:"chain_id"`
+	// Random is an optional per-block random beacon supplied by the chain (e.g. from the block's seed).
+	// It is identical on all validators. If set, it must be exactly RandomLength bytes.
+	Random []byte `json:"random,omitempty"`
+	// Proposer is the optional address of the validator that proposed the block, supplied by the chain.
+	// It is nil outside of block execution (e.g. in simulations).
+	Proposer CanonicalAddress `json:"proposer,omitempty"`
+}
+
+// RandomLength is the size of BlockInfo.Random in bytes
+const RandomLength = 32
+
+// Validate ensures a present random value has the expected length
+func (b BlockInfo) Validate() error {
+	if b.Random != nil && len(b.Random) != RandomLength {
+		return fmt.Errorf("block random must be %d bytes, got %d", RandomLength, len(b.Random))
+	}
+	return nil
+}
+
+// UnmarshalJSON rejects block info that is not valid, see Validate
+func (b *BlockInfo) UnmarshalJSON(data []byte) error {
+	// alias type to avoid recursion
+	type blockInfo BlockInfo
+	var raw blockInfo
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := BlockInfo(raw).Validate(); err != nil {
+		return err
+	}
+	*b = BlockInfo(raw)
+	return nil
 }
 
 type MessageInfo struct {
@@ -29,9 +84,33 @@ type MessageInfo struct {
 	Sender HumanAddress `json:"sender"`
 	// amount of funds send to the contract along with this message
 	SentFunds Coins `json:"sent_funds"`
+	// MsgIndex is the position of this message within its transaction, so contracts can
+	// order (and reject replays of) messages of one tx. This is nil for calls outside of a tx.
+	MsgIndex *uint32 `json:"msg_index,omitempty"`
+	// GasPrice is the minimum gas price of the chain, for fee aware contracts. It is a decimal coin
+	// (e.g. 0.25uscrt), and nil if the chain supplies no fee info (e.g. in queries).
+	// It must be the same on all validators, so never a node's locally configured minimum gas prices.
+	GasPrice *DecCoin `json:"gas_price,omitempty"`
 }
 
 type ContractInfo struct {
 	// binary encoding of sdk.AccAddress of the contract, to be used when sending messages
 	Address HumanAddress `json:"address"`
+	// human-readable label the contract was instantiated with, taken from the stored contract metadata
+	Label string `json:"label,omitempty"`
+}
+
+// MaxLabelSize is the maximum length (in bytes) of a contract label
+const MaxLabelSize = 128
+
+// ValidateLabel ensures a contract label is valid UTF-8 and no longer than MaxLabelSize.
+// This should be checked before the label is stored, so Env never carries an invalid one.
+func ValidateLabel(label string) error {
+	if !utf8.ValidString(label) {
+		return fmt.Errorf("label is not valid utf-8")
+	}
+	if len(label) > MaxLabelSize {
+		return fmt.Errorf("label too long: %d bytes (max %d)", len(label), MaxLabelSize)
+	}
+	return nil
 }
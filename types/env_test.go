@@ -1,7 +1,10 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,3 +50,183 @@ func TestMessageInfoHandlesMissingCoins(t *testing.T) {
 	require.True(t, ok)
 	assert.Equal(t, string(sent), "[]")
 }
+
+func TestContractInfoLabel(t *testing.T) {
+	info := ContractInfo{
+		Address: "contract",
+		Label:   "my sub-contract ✓",
+	}
+	bz, err := json.Marshal(info)
+	require.NoError(t, err)
+	assert.Equal(t, `{"address":"contract","label":"my sub-contract ✓"}`, string(bz))
+
+	var recover ContractInfo
+	err = json.Unmarshal(bz, &recover)
+	require.NoError(t, err)
+	assert.Equal(t, info, recover)
+
+	// label is left out when unset
+	bz, err = json.Marshal(ContractInfo{Address: "contract"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"address":"contract"}`, string(bz))
+}
+
+func TestValidateLabel(t *testing.T) {
+	require.NoError(t, ValidateLabel(""))
+	require.NoError(t, ValidateLabel("hello"))
+	require.NoError(t, ValidateLabel(strings.Repeat("a", MaxLabelSize)))
+
+	err := ValidateLabel(strings.Repeat("a", MaxLabelSize+1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "label too long")
+
+	err = ValidateLabel("bad \xff label")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "utf-8")
+}
+
+func TestBlockInfoRandom(t *testing.T) {
+	// absent randomness is omitted
+	info := BlockInfo{Height: 123, Time: 1578939743, ChainID: "foobar"}
+	bz, err := json.Marshal(info)
+	require.NoError(t, err)
+	assert.Equal(t, `{"height":123,"time":1578939743,"chain_id":"foobar"}`, string(bz))
+	var recover BlockInfo
+	err = json.Unmarshal(bz, &recover)
+	require.NoError(t, err)
+	assert.Equal(t, info, recover)
+	assert.Nil(t, recover.Random)
+
+	// present randomness is base64 encoded
+	info.Random = []byte(strings.Repeat("\x2a", RandomLength))
+	bz, err = json.Marshal(info)
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), `"random":"KioqKioqKioqKioqKioqKioqKioqKioqKioqKioqKio="`)
+	recover = BlockInfo{}
+	err = json.Unmarshal(bz, &recover)
+	require.NoError(t, err)
+	assert.Equal(t, info, recover)
+
+	// wrong length is rejected
+	err = json.Unmarshal([]byte(`{"height":123,"time":1578939743,"chain_id":"foobar","random":"KioqKg=="}`), &recover)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be 32 bytes, got 4")
+
+	// as is invalid base64
+	err = json.Unmarshal([]byte(`{"height":123,"time":1578939743,"chain_id":"foobar","random":"not base64!"}`), &recover)
+	require.Error(t, err)
+
+	// the host checks the length before sending it as well
+	assert.NoError(t, info.Validate())
+	assert.NoError(t, BlockInfo{}.Validate())
+	info.Random = info.Random[:4]
+	err = info.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be 32 bytes, got 4")
+}
+
+func TestBlockInfoLargeNumbers(t *testing.T) {
+	// not representable as float64
+	info := BlockInfo{Height: 1<<53 + 1, Time: math.MaxUint64, ChainID: "foobar"}
+	bz, err := json.Marshal(info)
+	require.NoError(t, err)
+	assert.Equal(t, `{"height":9007199254740993,"time":18446744073709551615,"chain_id":"foobar"}`, string(bz))
+
+	var recover BlockInfo
+	require.NoError(t, json.Unmarshal(bz, &recover))
+	assert.Equal(t, info, recover)
+
+	fromCBOR, err := MarshalCBOR(info)
+	require.NoError(t, err)
+	recover = BlockInfo{}
+	require.NoError(t, UnmarshalCBOR(fromCBOR, &recover))
+	assert.Equal(t, info, recover)
+
+	// generic decoding keeps the digits with UseNumber
+	dec := json.NewDecoder(bytes.NewReader(bz))
+	dec.UseNumber()
+	var generic map[string]interface{}
+	require.NoError(t, dec.Decode(&generic))
+	assert.Equal(t, json.Number("9007199254740993"), generic["height"])
+}
+
+func TestBlockInfoProposer(t *testing.T) {
+	// absent outside of blocks
+	var info BlockInfo
+	err := json.Unmarshal([]byte(`{"height":123,"time":1578939743,"chain_id":"foobar"}`), &info)
+	require.NoError(t, err)
+	assert.Nil(t, info.Proposer)
+	bz, err := json.Marshal(info)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bz), "proposer")
+
+	// present in blocks
+	info = BlockInfo{}
+	err = json.Unmarshal([]byte(`{"height":123,"time":1578939743,"chain_id":"foobar","proposer":"AAECAwQFBgcICQoLDA0ODxAREhM="}`), &info)
+	require.NoError(t, err)
+	assert.Equal(t, CanonicalAddress{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, info.Proposer)
+	bz, err = json.Marshal(info)
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), `"proposer":"AAECAwQFBgcICQoLDA0ODxAREhM="`)
+}
+
+func TestMessageInfoMsgIndex(t *testing.T) {
+	// absent for non-tx calls
+	var info MessageInfo
+	err := json.Unmarshal([]byte(`{"sender":"foobar","sent_funds":[]}`), &info)
+	require.NoError(t, err)
+	assert.Nil(t, info.MsgIndex)
+	bz, err := json.Marshal(info)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sender":"foobar","sent_funds":[]}`, string(bz))
+
+	// index 0 is present, not absent
+	err = json.Unmarshal([]byte(`{"sender":"foobar","sent_funds":[],"msg_index":0}`), &info)
+	require.NoError(t, err)
+	require.NotNil(t, info.MsgIndex)
+	assert.Equal(t, uint32(0), *info.MsgIndex)
+	bz, err = json.Marshal(info)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sender":"foobar","sent_funds":[],"msg_index":0}`, string(bz))
+
+	err = json.Unmarshal([]byte(`{"sender":"foobar","sent_funds":[],"msg_index":3}`), &info)
+	require.NoError(t, err)
+	require.NotNil(t, info.MsgIndex)
+	assert.Equal(t, uint32(3), *info.MsgIndex)
+}
+
+func TestMessageInfoGasPrice(t *testing.T) {
+	// absent without fee info
+	var info MessageInfo
+	err := json.Unmarshal([]byte(`{"sender":"foobar","sent_funds":[]}`), &info)
+	require.NoError(t, err)
+	assert.Nil(t, info.GasPrice)
+	bz, err := json.Marshal(info)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sender":"foobar","sent_funds":[]}`, string(bz))
+
+	// present
+	err = json.Unmarshal([]byte(`{"sender":"foobar","sent_funds":[],"gas_price":{"denom":"uscrt","amount":"0.25"}}`), &info)
+	require.NoError(t, err)
+	require.NotNil(t, info.GasPrice)
+	assert.Equal(t, DecCoin{Denom: "uscrt", Amount: "0.25"}, *info.GasPrice)
+	bz, err = json.Marshal(info)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sender":"foobar","sent_funds":[],"gas_price":{"denom":"uscrt","amount":"0.25"}}`, string(bz))
+}
+
+func TestEnvValidateKey(t *testing.T) {
+	env := Env{Key: "contract-key"}
+	assert.NoError(t, env.ValidateKey("contract-key"))
+
+	err := env.ValidateKey("other-key")
+	assert.EqualError(t, err, "contract key does not match the expected key")
+	err = env.ValidateKey("contract-key-")
+	assert.EqualError(t, err, "contract key does not match the expected key")
+	err = Env{}.ValidateKey("contract-key")
+	assert.EqualError(t, err, "contract key does not match the expected key")
+
+	// an empty key is never valid, not even against an env without one
+	assert.EqualError(t, env.ValidateKey(""), "expected contract key is empty")
+	assert.EqualError(t, Env{}.ValidateKey(""), "expected contract key is empty")
+}
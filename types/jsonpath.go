@@ -0,0 +1,121 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ResponseDecodeError is a failure to decode a contract response, with the JSON path of the
+// offending value, e.g. "Ok.messages[2].wasm.execute.contract_addr"
+type ResponseDecodeError struct {
+	Path string
+	Err  error
+}
+
+var _ error = ResponseDecodeError{}
+
+func (e ResponseDecodeError) Error() string {
+	return fmt.Sprintf("invalid contract response at %s: %v", e.Path, e.Err)
+}
+
+func (e ResponseDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorPath returns the JSON path of the innermost value in data that cannot be decoded
+// into (the type of) v, or "" if there is none or the json itself is malformed.
+// Struct fields are found by their json tag, falling back to a case insensitive match
+// of the field name like encoding/json does.
+func ErrorPath(data []byte, v interface{}) string {
+	path, _ := errorPath(data, reflect.TypeOf(v))
+	return path
+}
+
+// errorPath returns the path of the innermost failing value relative to data, and whether any failed
+func errorPath(data json.RawMessage, typ reflect.Type) (string, bool) {
+	for typ.Kind() == reflect.Ptr {
+		if string(data) == "null" {
+			return "", false
+		}
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ.Kind() == reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) == nil {
+			for i := 0; i < typ.NumField(); i++ {
+				field := typ.Field(i)
+				name := jsonFieldName(field)
+				if name == "" {
+					continue
+				}
+				raw, ok := lookupField(fields, name)
+				if !ok {
+					continue
+				}
+				if path, failed := errorPath(raw, field.Type); failed {
+					return joinPath(name, path), true
+				}
+			}
+		}
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) == nil {
+			for i, raw := range items {
+				if path, failed := errorPath(raw, typ.Elem()); failed {
+					return joinPath("["+strconv.Itoa(i)+"]", path), true
+				}
+			}
+		}
+	case typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String:
+		var items map[string]json.RawMessage
+		if json.Unmarshal(data, &items) == nil {
+			for key, raw := range items {
+				if path, failed := errorPath(raw, typ.Elem()); failed {
+					return joinPath(key, path), true
+				}
+			}
+		}
+	}
+
+	// no child failed, so it is either this value or none
+	return "", json.Unmarshal(data, reflect.New(typ).Interface()) != nil
+}
+
+// jsonFieldName returns the json name of an exported struct field, or "" if it is not decoded
+func jsonFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func lookupField(fields map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := fields[name]; ok {
+		return raw, true
+	}
+	for key, raw := range fields {
+		if strings.EqualFold(key, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+func joinPath(name string, path string) string {
+	if path == "" || strings.HasPrefix(path, "[") {
+		return name + path
+	}
+	return name + "." + path
+}
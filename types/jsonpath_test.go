@@ -0,0 +1,49 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorPath(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		path  string
+	}{
+		"valid":        {input: `{"Ok":{"messages":[],"log":[]}}`, path: ""},
+		"syntax error": {input: `{"Ok":{"messages":[`, path: ""},
+		"nested field": {
+			input: `{"Ok":{"messages":[{"bank":{"send":{"from_address":"a","to_address":"b","amount":[]}}},{"custom":{}},` +
+				`{"wasm":{"execute":{"contract_addr":17,"msg":"e30=","send":[]}}}],"log":[]}}`,
+			path: "Ok.messages[2].wasm.execute.contract_addr",
+		},
+		"custom decoder": {
+			input: `{"Ok":{"messages":[],"log":[{"key":"k","value":"v"}],"admin":"me"}}`,
+			path:  "Ok",
+		},
+		"list item": {input: `{"Ok":{"messages":[],"log":[{"key":"k","value":"v"},1]}}`, path: "Ok.log[1]"},
+		"binary":    {input: `{"Ok":{"messages":[],"log":[],"data":"not base64!"}}`, path: "Ok.data"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var resp HandleResult
+			assert.Equal(t, tc.path, ErrorPath([]byte(tc.input), &resp))
+		})
+	}
+}
+
+func TestResponseDecodeError(t *testing.T) {
+	input := []byte(`{"Ok":{"messages":[{"wasm":{"execute":{"contract_addr":17}}}]}}`)
+	var resp HandleResult
+	jsonErr := json.Unmarshal(input, &resp)
+	require.Error(t, jsonErr)
+
+	err := error(ResponseDecodeError{Path: ErrorPath(input, &resp), Err: jsonErr})
+	assert.Contains(t, err.Error(), "invalid contract response at Ok.messages[0].wasm.execute.contract_addr: json: cannot unmarshal number")
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+}
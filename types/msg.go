@@ -2,6 +2,9 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
 )
 
 //------- Results / Msgs -------------
@@ -16,10 +19,14 @@ type HandleResult struct {
 type HandleResponse struct {
 	// Messages comes directly from the contract and is it's request for action
 	Messages []CosmosMsg `json:"messages"`
-	// base64-encoded bytes to return as ABCI.Data field
+	// base64-encoded bytes to return as ABCI.Data field.
+	// This is nil if the contract did not set any data, and empty (but not nil)
+	// if it explicitly set it to an empty value.
 	Data []byte `json:"data"`
 	// log message to return over abci interface
 	Log []LogAttribute `json:"log"`
+	// RawResponse is the raw json returned by the contract, only set when capturing raw responses
+	RawResponse []byte `json:"-"`
 }
 
 // InitResult is the raw response from the handle call
@@ -28,12 +35,91 @@ type InitResult struct {
 	Err *StdError     `json:"Err,omitempty"`
 }
 
-// InitResponse defines the return value on a successful handle
+// InitResponse defines the return value on a successful init.
+// It has all fields of HandleResponse, plus some that only make sense during init.
 type InitResponse struct {
 	// Messages comes directly from the contract and is it's request for action
 	Messages []CosmosMsg `json:"messages"`
+	// base64-encoded bytes to return as ABCI.Data field.
+	// This is nil if the contract did not set any data, and empty (but not nil)
+	// if it explicitly set it to an empty value.
+	Data []byte `json:"data"`
 	// log message to return over abci interface
 	Log []LogAttribute `json:"log"`
+	// Admin is an optional address the contract sets as its admin (allowed to migrate it).
+	// This is init-only, other responses containing it are rejected.
+	Admin HumanAddress `json:"admin,omitempty"`
+	// RawResponse is the raw json returned by the contract, only set when capturing raw responses
+	RawResponse []byte `json:"-"`
+}
+
+// initOnlyFields are the json fields of InitResponse that are not valid in any other response
+var initOnlyFields = []string{"admin"}
+
+// rejectInitOnlyFields returns an error if the json object contains a field only valid in InitResponse
+func rejectInitOnlyFields(data []byte, response string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for _, field := range initOnlyFields {
+		if _, ok := fields[field]; ok {
+			return fmt.Errorf("%s must not contain init-only field %q", response, field)
+		}
+	}
+	return nil
+}
+
+// nonNilSlices replaces missing or null messages and log with empty slices,
+// so callers can range and index without nil checks
+func nonNilSlices(messages *[]CosmosMsg, log *[]LogAttribute) {
+	if *messages == nil {
+		*messages = []CosmosMsg{}
+	}
+	if *log == nil {
+		*log = []LogAttribute{}
+	}
+}
+
+// UnmarshalJSON decodes missing or null messages and log as empty slices
+func (r *InitResponse) UnmarshalJSON(data []byte) error {
+	// alias type to avoid recursion
+	type initResponse InitResponse
+	if err := json.Unmarshal(data, (*initResponse)(r)); err != nil {
+		return err
+	}
+	nonNilSlices(&r.Messages, &r.Log)
+	return nil
+}
+
+// UnmarshalJSON rejects init-only fields, which would otherwise be silently dropped,
+// and decodes missing or null messages and log as empty slices
+func (r *HandleResponse) UnmarshalJSON(data []byte) error {
+	if err := rejectInitOnlyFields(data, "handle response"); err != nil {
+		return err
+	}
+	// alias type to avoid recursion
+	type handleResponse HandleResponse
+	if err := json.Unmarshal(data, (*handleResponse)(r)); err != nil {
+		return err
+	}
+	nonNilSlices(&r.Messages, &r.Log)
+	return nil
+}
+
+// UnmarshalJSON rejects init-only fields, which would otherwise be silently dropped,
+// and decodes missing or null messages and log as empty slices
+func (r *MigrateResponse) UnmarshalJSON(data []byte) error {
+	if err := rejectInitOnlyFields(data, "migrate response"); err != nil {
+		return err
+	}
+	// alias type to avoid recursion
+	type migrateResponse MigrateResponse
+	if err := json.Unmarshal(data, (*migrateResponse)(r)); err != nil {
+		return err
+	}
+	nonNilSlices(&r.Messages, &r.Log)
+	return nil
 }
 
 // MigrateResult is the raw response from the handle call
@@ -46,16 +132,23 @@ type MigrateResult struct {
 type MigrateResponse struct {
 	// Messages comes directly from the contract and is it's request for action
 	Messages []CosmosMsg `json:"messages"`
-	// base64-encoded bytes to return as ABCI.Data field
+	// base64-encoded bytes to return as ABCI.Data field.
+	// This is nil if the contract did not set any data, and empty (but not nil)
+	// if it explicitly set it to an empty value.
 	Data []byte `json:"data"`
 	// log message to return over abci interface
 	Log []LogAttribute `json:"log"`
+	// RawResponse is the raw json returned by the contract, only set when capturing raw responses
+	RawResponse []byte `json:"-"`
 }
 
 // LogAttribute
 type LogAttribute struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// Plaintext is set by the contract for attributes that are public. All other attributes
+	// are encrypted by the enclave, which is also the default when the flag is absent.
+	Plaintext bool `json:"plaintext,omitempty"`
 }
 
 // CosmosMsg is an rust enum and only (exactly) one of the fields should be set
@@ -65,6 +158,7 @@ type CosmosMsg struct {
 	Custom  json.RawMessage `json:"custom,omitempty"`
 	Staking *StakingMsg     `json:"staking,omitempty"`
 	Wasm    *WasmMsg        `json:"wasm,omitempty"`
+	Gov     *GovMsg         `json:"gov,omitempty"`
 }
 
 type BankMsg struct {
@@ -79,6 +173,65 @@ type SendMsg struct {
 	Amount      Coins  `json:"amount"`
 }
 
+// BankSends returns all bank sends in msgs, in order, skipping any other kind of message
+func BankSends(msgs []CosmosMsg) []SendMsg {
+	var sends []SendMsg
+	for _, msg := range msgs {
+		if msg.Bank != nil && msg.Bank.Send != nil {
+			sends = append(sends, *msg.Bank.Send)
+		}
+	}
+	return sends
+}
+
+// BankSends returns all bank sends the contract requested, ready to be passed on to the bank keeper.
+// All other messages still have to be dispatched separately.
+func (r HandleResponse) BankSends() []SendMsg {
+	return BankSends(r.Messages)
+}
+
+// WalkMessages calls visit for every message of result, in order, stopping at the first error.
+// Messages are flat: there are no submessages, and the msg of a wasm execute is opaque
+// to the host, so what the callee dispatches only shows up in its own response.
+func WalkMessages(result CosmosResponse, visit func(msg CosmosMsg) error) error {
+	for _, msg := range result.messages() {
+		if err := visit(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeFunds normalizes the coins sent by bank sends and wasm messages in place:
+// zero amounts are dropped, duplicate denoms merged and the result sorted by denom.
+// It returns an error if any amount is not a valid uint128.
+func NormalizeFunds(msgs []CosmosMsg) error {
+	return NormalizeFundsBits(msgs, Uint128Bits)
+}
+
+// NormalizeFundsBits is like NormalizeFunds, but allows amounts of up to bits bits
+func NormalizeFundsBits(msgs []CosmosMsg, bits uint) error {
+	for i := range msgs {
+		var funds *Coins
+		switch msg := msgs[i]; {
+		case msg.Bank != nil && msg.Bank.Send != nil:
+			funds = &msg.Bank.Send.Amount
+		case msg.Wasm != nil && msg.Wasm.Execute != nil:
+			funds = &msg.Wasm.Execute.Send
+		case msg.Wasm != nil && msg.Wasm.Instantiate != nil:
+			funds = &msg.Wasm.Instantiate.Send
+		default:
+			continue
+		}
+		normalized, err := funds.NormalizeBits(bits)
+		if err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+		*funds = normalized.NonZero()
+	}
+	return nil
+}
+
 type StakingMsg struct {
 	Delegate   *DelegateMsg   `json:"delegate,omitempty"`
 	Undelegate *UndelegateMsg `json:"undelegate,omitempty"`
@@ -108,9 +261,104 @@ type WithdrawMsg struct {
 	Recipient string `json:"recipient,omitempty"`
 }
 
+type GovMsg struct {
+	Vote         *VoteMsg         `json:"vote,omitempty"`
+	VoteWeighted *VoteWeightedMsg `json:"vote_weighted,omitempty"`
+}
+
+// Validate checks the contents of the set variant
+func (m GovMsg) Validate() error {
+	if m.VoteWeighted != nil {
+		return m.VoteWeighted.Validate()
+	}
+	return nil
+}
+
+// VoteMsg casts a vote on a governance proposal, with the contract as the voter
+type VoteMsg struct {
+	ProposalID uint64     `json:"proposal_id"`
+	Vote       VoteOption `json:"vote"`
+}
+
+// VoteWeightedMsg casts a weighted vote on a governance proposal, splitting
+// the voting power of the contract between several options
+type VoteWeightedMsg struct {
+	ProposalID uint64               `json:"proposal_id"`
+	Options    []WeightedVoteOption `json:"options"`
+}
+
+type WeightedVoteOption struct {
+	Option VoteOption `json:"option"`
+	// decimal string, eg "0.25"
+	Weight string `json:"weight"`
+}
+
+// decimalRegexp matches the decimal strings sdk.Dec accepts
+var decimalRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]{1,18})?$`)
+
+// Validate ensures every option is listed once with a positive weight,
+// and that the weights add up to exactly 1. Weights must be sdk.Dec strings:
+// digits with an optional fractional part of at most 18 digits.
+func (m VoteWeightedMsg) Validate() error {
+	if len(m.Options) == 0 {
+		return fmt.Errorf("weighted vote needs at least one option")
+	}
+	one := big.NewRat(1, 1)
+	sum := new(big.Rat)
+	seen := make(map[VoteOption]bool, len(m.Options))
+	for _, opt := range m.Options {
+		if seen[opt.Option] {
+			return fmt.Errorf("duplicate vote option: %s", opt.Option)
+		}
+		seen[opt.Option] = true
+		if !decimalRegexp.MatchString(opt.Weight) {
+			return fmt.Errorf("invalid weight for %s: %q", opt.Option, opt.Weight)
+		}
+		weight, ok := new(big.Rat).SetString(opt.Weight)
+		if !ok {
+			return fmt.Errorf("invalid weight for %s: %q", opt.Option, opt.Weight)
+		}
+		if weight.Sign() <= 0 || weight.Cmp(one) > 0 {
+			return fmt.Errorf("weight for %s must be in (0, 1]: %s", opt.Option, opt.Weight)
+		}
+		sum.Add(sum, weight)
+	}
+	if sum.Cmp(one) != 0 {
+		return fmt.Errorf("vote weights must sum to 1, got %s", sum.FloatString(18))
+	}
+	return nil
+}
+
+// VoteOption is an enum of the possible votes on a governance proposal
+type VoteOption string
+
+const (
+	Yes        VoteOption = "yes"
+	No         VoteOption = "no"
+	Abstain    VoteOption = "abstain"
+	NoWithVeto VoteOption = "no_with_veto"
+)
+
+// UnmarshalJSON ensures only the known vote options are accepted
+func (v *VoteOption) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch opt := VoteOption(raw); opt {
+	case Yes, No, Abstain, NoWithVeto:
+		*v = opt
+		return nil
+	default:
+		return fmt.Errorf("unknown vote option: %q", raw)
+	}
+}
+
 type WasmMsg struct {
 	Execute     *ExecuteMsg     `json:"execute,omitempty"`
 	Instantiate *InstantiateMsg `json:"instantiate,omitempty"`
+	UpdateAdmin *UpdateAdminMsg `json:"update_admin,omitempty"`
+	ClearAdmin  *ClearAdminMsg  `json:"clear_admin,omitempty"`
 }
 
 // ExecuteMsg is used to call another defined contract on this chain.
@@ -148,3 +396,20 @@ type InstantiateMsg struct {
 	// Send is an optional amount of coins this contract sends to the called contract
 	Send Coins `json:"send"`
 }
+
+// UpdateAdminMsg sets a new admin (allowed to migrate) for a contract.
+// Only the current admin of the contract may send it.
+type UpdateAdminMsg struct {
+	// ContractAddr is the sdk.AccAddress of the contract to update
+	ContractAddr string `json:"contract_addr"`
+	// Admin is the sdk.AccAddress of the new admin
+	Admin string `json:"admin"`
+}
+
+// ClearAdminMsg removes the admin of a contract, making it immutable.
+// A contract that is its own admin can send it to terminate itself, after which
+// the keeper may reclaim its state. Only the current admin of the contract may send it.
+type ClearAdminMsg struct {
+	// ContractAddr is the sdk.AccAddress of the contract to clear the admin of
+	ContractAddr string `json:"contract_addr"`
+}
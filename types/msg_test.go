@@ -0,0 +1,383 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleResponseDataNilVsEmpty(t *testing.T) {
+	cases := map[string]struct {
+		input    string
+		expected []byte
+		isNil    bool
+	}{
+		"missing": {input: `{"messages":[],"log":[]}`, isNil: true},
+		"null":    {input: `{"messages":[],"data":null,"log":[]}`, isNil: true},
+		"empty":   {input: `{"messages":[],"data":"","log":[]}`, expected: []byte{}},
+		"set":     {input: `{"messages":[],"data":"8Auq","log":[]}`, expected: []byte{0xF0, 0x0B, 0xAA}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var resp HandleResponse
+			err := json.Unmarshal([]byte(tc.input), &resp)
+			require.NoError(t, err)
+			if tc.isNil {
+				assert.Nil(t, resp.Data)
+			} else {
+				require.NotNil(t, resp.Data)
+				assert.Equal(t, tc.expected, resp.Data)
+			}
+
+			// and the distinction survives a round trip
+			bz, err := json.Marshal(resp)
+			require.NoError(t, err)
+			var recover HandleResponse
+			err = json.Unmarshal(bz, &recover)
+			require.NoError(t, err)
+			assert.Equal(t, resp.Data == nil, recover.Data == nil)
+			assert.Equal(t, resp.Data, recover.Data)
+		})
+	}
+}
+
+func TestResponseSlicesNeverNil(t *testing.T) {
+	cases := map[string]string{
+		"missing": `{}`,
+		"null":    `{"messages":null,"log":null}`,
+		"empty":   `{"messages":[],"log":[]}`,
+	}
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			var init InitResponse
+			require.NoError(t, json.Unmarshal([]byte(input), &init))
+			assert.Equal(t, []CosmosMsg{}, init.Messages)
+			assert.Equal(t, []LogAttribute{}, init.Log)
+
+			var handle HandleResponse
+			require.NoError(t, json.Unmarshal([]byte(input), &handle))
+			assert.Equal(t, []CosmosMsg{}, handle.Messages)
+			assert.Equal(t, []LogAttribute{}, handle.Log)
+
+			var migrate MigrateResponse
+			require.NoError(t, json.Unmarshal([]byte(input), &migrate))
+			assert.Equal(t, []CosmosMsg{}, migrate.Messages)
+			assert.Equal(t, []LogAttribute{}, migrate.Log)
+
+			// also inside a result
+			var result HandleResult
+			require.NoError(t, json.Unmarshal([]byte(`{"Ok":`+input+`}`), &result))
+			assert.Equal(t, []CosmosMsg{}, result.Ok.Messages)
+		})
+	}
+}
+
+func TestLogAttributePlaintext(t *testing.T) {
+	var result HandleResult
+	err := json.Unmarshal([]byte(`{"Ok":{"messages":[],"log":[`+
+		`{"key":"action","value":"transfer","plaintext":true},`+
+		`{"key":"recipient","value":"secret1recipient"},`+
+		`{"key":"amount","value":"100","plaintext":false}]}}`), &result)
+	require.NoError(t, err)
+	expected := []LogAttribute{
+		{Key: "action", Value: "transfer", Plaintext: true},
+		{Key: "recipient", Value: "secret1recipient"},
+		{Key: "amount", Value: "100"},
+	}
+	assert.Equal(t, expected, result.Ok.Log)
+
+	// encrypted attributes encode as before
+	bz, err := json.Marshal(result.Ok.Log)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"key":"action","value":"transfer","plaintext":true},{"key":"recipient","value":"secret1recipient"},{"key":"amount","value":"100"}]`, string(bz))
+}
+
+func TestInitResponseDataNilVsEmpty(t *testing.T) {
+	var unset InitResponse
+	err := json.Unmarshal([]byte(`{"messages":[],"log":[]}`), &unset)
+	require.NoError(t, err)
+	assert.Nil(t, unset.Data)
+
+	var empty InitResponse
+	err = json.Unmarshal([]byte(`{"messages":[],"data":"","log":[]}`), &empty)
+	require.NoError(t, err)
+	require.NotNil(t, empty.Data)
+	assert.Equal(t, 0, len(empty.Data))
+}
+
+func TestGovVoteMsgRoundTrip(t *testing.T) {
+	for _, option := range []VoteOption{Yes, No, Abstain, NoWithVeto} {
+		t.Run(string(option), func(t *testing.T) {
+			msg := CosmosMsg{
+				Gov: &GovMsg{
+					Vote: &VoteMsg{ProposalID: 17, Vote: option},
+				},
+			}
+			bz, err := json.Marshal(msg)
+			require.NoError(t, err)
+			assert.Equal(t, `{"gov":{"vote":{"proposal_id":17,"vote":"`+string(option)+`"}}}`, string(bz))
+
+			var recover CosmosMsg
+			err = json.Unmarshal(bz, &recover)
+			require.NoError(t, err)
+			assert.Equal(t, msg, recover)
+		})
+	}
+}
+
+func TestWasmAdminMsgRoundTrip(t *testing.T) {
+	cases := map[string]struct {
+		msg      CosmosMsg
+		expected string
+	}{
+		"update admin": {
+			msg:      CosmosMsg{Wasm: &WasmMsg{UpdateAdmin: &UpdateAdminMsg{ContractAddr: "contract", Admin: "new_admin"}}},
+			expected: `{"wasm":{"update_admin":{"contract_addr":"contract","admin":"new_admin"}}}`,
+		},
+		"clear admin": {
+			msg:      CosmosMsg{Wasm: &WasmMsg{ClearAdmin: &ClearAdminMsg{ContractAddr: "contract"}}},
+			expected: `{"wasm":{"clear_admin":{"contract_addr":"contract"}}}`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			bz, err := json.Marshal(tc.msg)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(bz))
+
+			var recover CosmosMsg
+			err = json.Unmarshal(bz, &recover)
+			require.NoError(t, err)
+			assert.Equal(t, tc.msg, recover)
+		})
+	}
+}
+
+func TestGovVoteMsgRejectsUnknownOption(t *testing.T) {
+	var msg CosmosMsg
+	err := json.Unmarshal([]byte(`{"gov":{"vote":{"proposal_id":17,"vote":"maybe"}}}`), &msg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown vote option: "maybe"`)
+}
+
+func TestGovVoteWeightedMsgRoundTrip(t *testing.T) {
+	msg := CosmosMsg{
+		Gov: &GovMsg{
+			VoteWeighted: &VoteWeightedMsg{
+				ProposalID: 4,
+				Options: []WeightedVoteOption{
+					{Option: Yes, Weight: "0.7"},
+					{Option: Abstain, Weight: "0.3"},
+				},
+			},
+		},
+	}
+	bz, err := json.Marshal(msg)
+	require.NoError(t, err)
+	assert.Equal(t, `{"gov":{"vote_weighted":{"proposal_id":4,"options":[{"option":"yes","weight":"0.7"},{"option":"abstain","weight":"0.3"}]}}}`, string(bz))
+
+	var recover CosmosMsg
+	err = json.Unmarshal(bz, &recover)
+	require.NoError(t, err)
+	assert.Equal(t, msg, recover)
+	require.NoError(t, recover.Gov.Validate())
+}
+
+func TestGovVoteWeightedValidate(t *testing.T) {
+	cases := map[string]struct {
+		options []WeightedVoteOption
+		valid   bool
+	}{
+		"single full weight": {
+			options: []WeightedVoteOption{{Option: No, Weight: "1"}},
+			valid:   true,
+		},
+		"thirds": {
+			options: []WeightedVoteOption{
+				{Option: Yes, Weight: "0.333333333333333333"},
+				{Option: No, Weight: "0.333333333333333333"},
+				{Option: Abstain, Weight: "0.333333333333333334"},
+			},
+			valid: true,
+		},
+		"sum below one": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "0.5"}, {Option: No, Weight: "0.4"}},
+		},
+		"sum above one": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "0.5"}, {Option: No, Weight: "0.6"}},
+		},
+		"zero weight": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "1"}, {Option: No, Weight: "0"}},
+		},
+		"negative weight": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "1.5"}, {Option: No, Weight: "-0.5"}},
+		},
+		"duplicate option": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "0.5"}, {Option: Yes, Weight: "0.5"}},
+		},
+		"bad decimal": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "one"}},
+		},
+		"fraction": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "1/2"}, {Option: No, Weight: "1/2"}},
+		},
+		"exponent": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "1e-1"}, {Option: No, Weight: "0.9"}},
+		},
+		"plus sign": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "+0.5"}, {Option: No, Weight: "0.5"}},
+		},
+		"too many decimals": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "0.5000000000000000000"}, {Option: No, Weight: "0.5"}},
+		},
+		"trailing dot": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: "1."}},
+		},
+		"leading dot": {
+			options: []WeightedVoteOption{{Option: Yes, Weight: ".5"}, {Option: No, Weight: "0.5"}},
+		},
+		"no options": {},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			msg := GovMsg{VoteWeighted: &VoteWeightedMsg{ProposalID: 1, Options: tc.options}}
+			err := msg.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestInitResponseAdmin(t *testing.T) {
+	var resp InitResponse
+	err := json.Unmarshal([]byte(`{"messages":[],"log":[],"admin":"admin-addr"}`), &resp)
+	require.NoError(t, err)
+	assert.Equal(t, HumanAddress("admin-addr"), resp.Admin)
+
+	// not set is fine as well, and stays omitted
+	resp = InitResponse{}
+	err = json.Unmarshal([]byte(`{"messages":[],"log":[]}`), &resp)
+	require.NoError(t, err)
+	assert.Equal(t, HumanAddress(""), resp.Admin)
+	bz, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bz), "admin")
+}
+
+func TestResponsesRejectInitOnlyFields(t *testing.T) {
+	input := []byte(`{"Ok":{"messages":[],"log":[],"admin":"admin-addr"}}`)
+
+	var handle HandleResult
+	err := json.Unmarshal(input, &handle)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `handle response must not contain init-only field "admin"`)
+
+	var migrate MigrateResult
+	err = json.Unmarshal(input, &migrate)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `migrate response must not contain init-only field "admin"`)
+
+	// a normal handle response still decodes
+	err = json.Unmarshal([]byte(`{"Ok":{"messages":[],"data":"AQI=","log":[{"key":"a","value":"b"}]}}`), &handle)
+	require.NoError(t, err)
+	require.NotNil(t, handle.Ok)
+	assert.Equal(t, []byte{1, 2}, handle.Ok.Data)
+	assert.Equal(t, []LogAttribute{{Key: "a", Value: "b"}}, handle.Ok.Log)
+}
+
+func TestHandleResponseBankSends(t *testing.T) {
+	send1 := SendMsg{FromAddress: "contract", ToAddress: "alice", Amount: Coins{{Denom: "uscrt", Amount: "100"}}}
+	send2 := SendMsg{FromAddress: "contract", ToAddress: "bob", Amount: Coins{{Denom: "uscrt", Amount: "5"}, {Denom: "ufoo", Amount: "7"}}}
+	resp := HandleResponse{
+		Messages: []CosmosMsg{
+			{Bank: &BankMsg{Send: &send1}},
+			{Staking: &StakingMsg{Delegate: &DelegateMsg{Validator: "val", Amount: Coin{Denom: "uscrt", Amount: "1"}}}},
+			{Wasm: &WasmMsg{Execute: &ExecuteMsg{ContractAddr: "other", Msg: []byte(`{}`)}}},
+			{Bank: &BankMsg{}},
+			{Bank: &BankMsg{Send: &send2}},
+		},
+	}
+	assert.Equal(t, []SendMsg{send1, send2}, resp.BankSends())
+
+	// no sends at all
+	assert.Empty(t, HandleResponse{}.BankSends())
+	assert.Empty(t, BankSends(resp.Messages[1:4]))
+}
+
+func TestNormalizeFunds(t *testing.T) {
+	msgs := []CosmosMsg{
+		{Bank: &BankMsg{Send: &SendMsg{FromAddress: "contract", ToAddress: "alice", Amount: Coins{
+			{Denom: "uscrt", Amount: "100"},
+			{Denom: "ufoo", Amount: "0"},
+			{Denom: "uscrt", Amount: "23"},
+		}}}},
+		{Wasm: &WasmMsg{Execute: &ExecuteMsg{ContractAddr: "other", Msg: []byte(`{}`), Send: Coins{
+			{Denom: "uscrt", Amount: "0"},
+		}}}},
+		{Wasm: &WasmMsg{Instantiate: &InstantiateMsg{CodeID: 1, Msg: []byte(`{}`), Label: "x", Send: Coins{
+			{Denom: "ufoo", Amount: "5"},
+			{Denom: "ubar", Amount: "1"},
+			{Denom: "ufoo", Amount: "5"},
+		}}}},
+		{Staking: &StakingMsg{Delegate: &DelegateMsg{Validator: "val", Amount: Coin{Denom: "uscrt", Amount: "0"}}}},
+	}
+	require.NoError(t, NormalizeFunds(msgs))
+	assert.Equal(t, Coins{{Denom: "uscrt", Amount: "123"}}, msgs[0].Bank.Send.Amount)
+	assert.Empty(t, msgs[1].Wasm.Execute.Send)
+	assert.Equal(t, Coins{{Denom: "ubar", Amount: "1"}, {Denom: "ufoo", Amount: "10"}}, msgs[2].Wasm.Instantiate.Send)
+	// only sends are touched
+	assert.Equal(t, "0", msgs[3].Staking.Delegate.Amount.Amount)
+
+	// invalid amounts are rejected
+	bad := []CosmosMsg{
+		{Bank: &BankMsg{Send: &SendMsg{Amount: Coins{{Denom: "uscrt", Amount: "1"}}}}},
+		{Bank: &BankMsg{Send: &SendMsg{Amount: Coins{{Denom: "uscrt", Amount: "-1"}}}}},
+	}
+	err := NormalizeFunds(bad)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "message 1")
+}
+
+func TestWalkMessages(t *testing.T) {
+	msgs := []CosmosMsg{
+		{Bank: &BankMsg{Send: &SendMsg{FromAddress: "contract", ToAddress: "alice"}}},
+		{Wasm: &WasmMsg{Execute: &ExecuteMsg{ContractAddr: "other", Msg: []byte(`{"forward":{}}`)}}},
+		{Custom: json.RawMessage(`{"foo":"bar"}`)},
+	}
+
+	var visited []CosmosMsg
+	visit := func(msg CosmosMsg) error {
+		visited = append(visited, msg)
+		return nil
+	}
+	require.NoError(t, WalkMessages(HandleResponse{Messages: msgs}, visit))
+	assert.Equal(t, msgs, visited)
+
+	visited = nil
+	require.NoError(t, WalkMessages(&InitResponse{Messages: msgs[1:]}, visit))
+	assert.Equal(t, msgs[1:], visited)
+
+	visited = nil
+	require.NoError(t, WalkMessages(MigrateResponse{}, visit))
+	assert.Empty(t, visited)
+
+	// stops on the first error
+	count := 0
+	err := WalkMessages(HandleResponse{Messages: msgs}, func(msg CosmosMsg) error {
+		count++
+		if msg.Wasm != nil {
+			return fmt.Errorf("no wasm calls allowed")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "no wasm calls allowed")
+	assert.Equal(t, 2, count)
+}
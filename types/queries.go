@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 //-------- Queries --------
@@ -9,6 +10,52 @@ import (
 type QueryResponse struct {
 	Ok  []byte    `json:"Ok,omitempty"`
 	Err *StdError `json:"Err,omitempty"`
+	// Events are optionally emitted by newer contracts for tracing, even from queries.
+	// They never affect state, and are nil for responses without events.
+	Events []Event `json:"events,omitempty"`
+}
+
+// MarshalJSON distinguishes a nil Ok, encoded as null, from an empty one, encoded as "".
+// Contracts treat these differently, so Ok is only left out for an Err response.
+func (q QueryResponse) MarshalJSON() ([]byte, error) {
+	if q.Err != nil {
+		return json.Marshal(struct {
+			Err    *StdError `json:"Err"`
+			Events []Event   `json:"events,omitempty"`
+		}{q.Err, q.Events})
+	}
+	return json.Marshal(struct {
+		Ok     []byte  `json:"Ok"`
+		Events []Event `json:"events,omitempty"`
+	}{q.Ok, q.Events})
+}
+
+// ContractVersionInfo is the self reported name and version of a contract
+type ContractVersionInfo struct {
+	// Contract is the name of the contract, e.g. "crates.io:snip20"
+	Contract string `json:"contract"`
+	Version  string `json:"version"`
+}
+
+// Event is a typed group of attributes emitted by a contract
+type Event struct {
+	Type       string         `json:"type"`
+	Attributes []LogAttribute `json:"attributes"`
+}
+
+// DefaultEventTypePrefix is prefixed to the type of contract events, following the ecosystem convention
+const DefaultEventTypePrefix = "wasm-"
+
+// PrefixEventTypes returns the events with prefix added to every event type
+func PrefixEventTypes(events []Event, prefix string) []Event {
+	if prefix == "" || len(events) == 0 {
+		return events
+	}
+	res := make([]Event, len(events))
+	for i, event := range events {
+		res[i] = Event{Type: prefix + event.Type, Attributes: event.Attributes}
+	}
+	return res
 }
 
 //-------- Querier -----------
@@ -57,18 +104,47 @@ func ToQuerierResult(response []byte, err error) QuerierResult {
 	}
 }
 
+// QueryFailedError is returned for a contract call that failed after one of the contract's own queries
+// failed, so the caller can tell why (see ReportQueryFailures). Exactly one of System and Contract is set.
+type QueryFailedError struct {
+	// Err is the error of the contract call itself
+	Err error
+	// Request is the first query that failed
+	Request QueryRequest
+	// System is set if the host could not answer the query (e.g. NoSuchContract)
+	System *SystemError
+	// Contract is set if the query was answered with an error (e.g. the queried contract errored)
+	Contract *StdError
+}
+
+var _ error = QueryFailedError{}
+
+func (e QueryFailedError) Error() string {
+	if e.System != nil {
+		return fmt.Sprintf("%v (query failed: %v)", e.Err, e.System)
+	}
+	return fmt.Sprintf("%v (query returned error: %v)", e.Err, e.Contract)
+}
+
+// Unwrap returns the error of the contract call
+func (e QueryFailedError) Unwrap() error {
+	return e.Err
+}
+
 // QueryRequest is an rust enum and only (exactly) one of the fields should be set
 // Should we do a cleaner approach in Go? (type/data?)
 type QueryRequest struct {
-	Bank    *BankQuery      `json:"bank,omitempty"`
-	Custom  json.RawMessage `json:"custom,omitempty"`
-	Staking *StakingQuery   `json:"staking,omitempty"`
-	Wasm    *WasmQuery      `json:"wasm,omitempty"`
+	Bank         *BankQuery         `json:"bank,omitempty"`
+	Custom       json.RawMessage    `json:"custom,omitempty"`
+	Staking      *StakingQuery      `json:"staking,omitempty"`
+	Distribution *DistributionQuery `json:"distribution,omitempty"`
+	Wasm         *WasmQuery         `json:"wasm,omitempty"`
 }
 
 type BankQuery struct {
 	Balance     *BalanceQuery     `json:"balance,omitempty"`
 	AllBalances *AllBalancesQuery `json:"all_balances,omitempty"`
+	Supply      *SupplyQuery      `json:"supply,omitempty"`
 }
 
 type BalanceQuery struct {
@@ -90,6 +166,16 @@ type AllBalancesResponse struct {
 	Amount Coins `json:"amount"`
 }
 
+// SupplyQuery returns the total supply of a denom on the chain
+type SupplyQuery struct {
+	Denom string `json:"denom"`
+}
+
+// SupplyResponse is the expected response to SupplyQuery
+type SupplyResponse struct {
+	Amount Coin `json:"amount"`
+}
+
 type StakingQuery struct {
 	Validators     *ValidatorsQuery     `json:"validators,omitempty"`
 	AllDelegations *AllDelegationsQuery `json:"all_delegations,omitempty"`
@@ -202,6 +288,30 @@ type BondedDenomResponse struct {
 	Denom string `json:"denom"`
 }
 
+type DistributionQuery struct {
+	DelegatorWithdrawAddress *DelegatorWithdrawAddressQuery `json:"delegator_withdraw_address,omitempty"`
+	DelegationRewards        *DelegationRewardsQuery        `json:"delegation_rewards,omitempty"`
+}
+
+type DelegatorWithdrawAddressQuery struct {
+	DelegatorAddress string `json:"delegator_address"`
+}
+
+// DelegatorWithdrawAddressResponse is the expected response to DelegatorWithdrawAddressQuery
+type DelegatorWithdrawAddressResponse struct {
+	WithdrawAddress string `json:"withdraw_address"`
+}
+
+type DelegationRewardsQuery struct {
+	DelegatorAddress string `json:"delegator_address"`
+	ValidatorAddress string `json:"validator_address"`
+}
+
+// DelegationRewardsResponse is the expected response to DelegationRewardsQuery
+type DelegationRewardsResponse struct {
+	Rewards DecCoins `json:"rewards"`
+}
+
 type WasmQuery struct {
 	Smart *SmartQuery `json:"smart,omitempty"`
 	Raw   *RawQuery   `json:"raw,omitempty"`
@@ -210,7 +320,10 @@ type WasmQuery struct {
 // SmartQuery respone is raw bytes ([]byte)
 type SmartQuery struct {
 	ContractAddr string `json:"contract_addr"`
-	Msg          []byte `json:"msg"`
+	// CallbackCodeHash is the code hash of the queried contract, which the enclave needs to
+	// decrypt the query for it. See api.InjectCodeHash to fill it in.
+	CallbackCodeHash string `json:"callback_code_hash,omitempty"`
+	Msg              []byte `json:"msg"`
 }
 
 // RawQuery response is raw bytes ([]byte)
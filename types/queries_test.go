@@ -61,3 +61,139 @@ func TestValidatorWithData(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, reval, val)
 }
+
+func TestDistributionQueryEncoding(t *testing.T) {
+	withdraw := []byte(`{"distribution":{"delegator_withdraw_address":{"delegator_address":"bob"}}}`)
+	var req QueryRequest
+	err := json.Unmarshal(withdraw, &req)
+	require.NoError(t, err)
+	require.NotNil(t, req.Distribution)
+	require.NotNil(t, req.Distribution.DelegatorWithdrawAddress)
+	assert.Nil(t, req.Distribution.DelegationRewards)
+	assert.Equal(t, "bob", req.Distribution.DelegatorWithdrawAddress.DelegatorAddress)
+	bz, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.Equal(t, string(withdraw), string(bz))
+
+	rewards := []byte(`{"distribution":{"delegation_rewards":{"delegator_address":"bob","validator_address":"val"}}}`)
+	req = QueryRequest{}
+	err = json.Unmarshal(rewards, &req)
+	require.NoError(t, err)
+	require.NotNil(t, req.Distribution)
+	require.NotNil(t, req.Distribution.DelegationRewards)
+	assert.Nil(t, req.Distribution.DelegatorWithdrawAddress)
+	assert.Equal(t, "bob", req.Distribution.DelegationRewards.DelegatorAddress)
+	assert.Equal(t, "val", req.Distribution.DelegationRewards.ValidatorAddress)
+	bz, err = json.Marshal(req)
+	require.NoError(t, err)
+	assert.Equal(t, string(rewards), string(bz))
+}
+
+func TestDistributionResponseEncoding(t *testing.T) {
+	withdraw := DelegatorWithdrawAddressResponse{WithdrawAddress: "alice"}
+	bz, err := json.Marshal(withdraw)
+	require.NoError(t, err)
+	assert.Equal(t, `{"withdraw_address":"alice"}`, string(bz))
+
+	rewards := DelegationRewardsResponse{
+		Rewards: DecCoins{{Denom: "uscrt", Amount: "123.456"}},
+	}
+	bz, err = json.Marshal(rewards)
+	require.NoError(t, err)
+	assert.Equal(t, `{"rewards":[{"denom":"uscrt","amount":"123.456"}]}`, string(bz))
+
+	var recover DelegationRewardsResponse
+	err = json.Unmarshal(bz, &recover)
+	require.NoError(t, err)
+	assert.Equal(t, rewards, recover)
+
+	// no rewards still encodes as []
+	bz, err = json.Marshal(DelegationRewardsResponse{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"rewards":[]}`, string(bz))
+}
+
+func TestSupplyQueryEncoding(t *testing.T) {
+	supply := []byte(`{"bank":{"supply":{"denom":"uscrt"}}}`)
+	var req QueryRequest
+	err := json.Unmarshal(supply, &req)
+	require.NoError(t, err)
+	require.NotNil(t, req.Bank)
+	require.NotNil(t, req.Bank.Supply)
+	assert.Nil(t, req.Bank.Balance)
+	assert.Nil(t, req.Bank.AllBalances)
+	assert.Equal(t, "uscrt", req.Bank.Supply.Denom)
+	bz, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.Equal(t, string(supply), string(bz))
+
+	resp := SupplyResponse{Amount: NewCoin(1000000, "uscrt")}
+	bz, err = json.Marshal(resp)
+	require.NoError(t, err)
+	assert.Equal(t, `{"amount":{"denom":"uscrt","amount":"1000000"}}`, string(bz))
+
+	var recover SupplyResponse
+	err = json.Unmarshal(bz, &recover)
+	require.NoError(t, err)
+	assert.Equal(t, resp, recover)
+}
+
+func TestQueryResponseEvents(t *testing.T) {
+	// older contracts send no events
+	var resp QueryResponse
+	err := json.Unmarshal([]byte(`{"Ok":"eyJjb3VudCI6MX0="}`), &resp)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"count":1}`), resp.Ok)
+	assert.Nil(t, resp.Events)
+	bz, err := json.Marshal(resp)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Ok":"eyJjb3VudCI6MX0="}`, string(bz))
+
+	// newer ones may
+	resp = QueryResponse{}
+	err = json.Unmarshal([]byte(`{"Ok":"eyJjb3VudCI6MX0=","events":[{"type":"trace","attributes":[{"key":"loaded","value":"counter"}]}]}`), &resp)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"count":1}`), resp.Ok)
+	assert.Equal(t, []Event{{Type: "trace", Attributes: []LogAttribute{{Key: "loaded", Value: "counter"}}}}, resp.Events)
+}
+
+func TestPrefixEventTypes(t *testing.T) {
+	attrs := []LogAttribute{{Key: "loaded", Value: "counter"}}
+	events := []Event{{Type: "trace", Attributes: attrs}, {Type: "transfer"}}
+
+	prefixed := PrefixEventTypes(events, DefaultEventTypePrefix)
+	assert.Equal(t, []Event{{Type: "wasm-trace", Attributes: attrs}, {Type: "wasm-transfer"}}, prefixed)
+	// the input is not modified
+	assert.Equal(t, "trace", events[0].Type)
+
+	assert.Equal(t, "my-trace", PrefixEventTypes(events, "my-")[0].Type)
+	assert.Equal(t, events, PrefixEventTypes(events, ""))
+	assert.Nil(t, PrefixEventTypes(nil, DefaultEventTypePrefix))
+}
+
+func TestQueryResponseOkEncoding(t *testing.T) {
+	cases := map[string]struct {
+		resp QueryResponse
+		json string
+		ok   []byte
+	}{
+		"nil":   {resp: QueryResponse{}, json: `{"Ok":null}`},
+		"empty": {resp: QueryResponse{Ok: []byte{}}, json: `{"Ok":""}`, ok: []byte{}},
+		"data":  {resp: QueryResponse{Ok: []byte(`{}`)}, json: `{"Ok":"e30="}`, ok: []byte(`{}`)},
+		"err": {
+			resp: QueryResponse{Ok: []byte{}, Err: &StdError{NotFound: &NotFound{Kind: "foo"}}},
+			json: `{"Err":{"not_found":{"kind":"foo"}}}`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			bz, err := json.Marshal(tc.resp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.json, string(bz))
+
+			var decoded QueryResponse
+			require.NoError(t, json.Unmarshal(bz, &decoded))
+			assert.Equal(t, tc.ok, decoded.Ok)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// +build sdk
+
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// This file is only built with the `sdk` build tag, so that importing this package
+// does not pull in the cosmos-sdk as a hard dependency.
+
+// FromSDKCoins converts sdk.Coins into the string based Coins passed to contracts
+func FromSDKCoins(coins sdk.Coins) Coins {
+	res := make(Coins, len(coins))
+	for i, c := range coins {
+		res[i] = Coin{
+			Denom:  c.Denom,
+			Amount: c.Amount.String(),
+		}
+	}
+	return res
+}
+
+// ToSDKCoins converts Coins (e.g. from a contract message) into sdk.Coins.
+// It returns an error if any amount is not an integer, or if the resulting
+// coins are not valid sdk coins (bad denom, non-positive amount, duplicate denom).
+func ToSDKCoins(coins []Coin) (sdk.Coins, error) {
+	res := make(sdk.Coins, 0, len(coins))
+	for _, c := range coins {
+		amount, ok := sdk.NewIntFromString(c.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount for %s: %q is not an integer", c.Denom, c.Amount)
+		}
+		res = append(res, sdk.Coin{Denom: c.Denom, Amount: amount})
+	}
+	res = res.Sort()
+	if !res.IsValid() {
+		return nil, fmt.Errorf("invalid coins: %s", res)
+	}
+	return res, nil
+}
+
+// EnvFromContext builds the Env for a call to the contract at contractAddr, which receives sentFunds.
+// Block info, including the proposer if any, is taken from the (trusted) context only. Message.Sender is left empty: the caller sets it
+// to the verified signer of the message, as it is not part of the context. The same goes for MsgIndex,
+// the contract label and key, which come from the keeper's own records.
+func EnvFromContext(ctx sdk.Context, contractAddr sdk.AccAddress, sentFunds sdk.Coins) Env {
+	// safety checks before casting below
+	if ctx.BlockHeight() < 0 {
+		panic("block height must never be negative")
+	}
+	sec := ctx.BlockTime().Unix()
+	if sec < 0 {
+		panic("block time must never be before 1970")
+	}
+	var proposer CanonicalAddress
+	if addr := ctx.BlockHeader().ProposerAddress; len(addr) > 0 {
+		proposer = addr
+	}
+	return Env{
+		Block: BlockInfo{
+			Height:   uint64(ctx.BlockHeight()),
+			Time:     uint64(sec),
+			ChainID:  ctx.ChainID(),
+			Proposer: proposer,
+		},
+		Message: MessageInfo{
+			SentFunds: FromSDKCoins(sentFunds),
+		},
+		Contract: ContractInfo{
+			Address: HumanAddress(contractAddr.String()),
+		},
+	}
+}
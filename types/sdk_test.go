@@ -0,0 +1,89 @@
+// +build sdk
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDKCoinsRoundTrip(t *testing.T) {
+	big, ok := sdk.NewIntFromString("340282366920938463463374607431768211455")
+	require.True(t, ok)
+	orig := sdk.NewCoins(sdk.NewInt64Coin("uatom", 789876), sdk.NewCoin("peth", big))
+
+	coins := FromSDKCoins(orig)
+	assert.Equal(t, Coins{
+		{Denom: "peth", Amount: "340282366920938463463374607431768211455"},
+		{Denom: "uatom", Amount: "789876"},
+	}, coins)
+
+	recover, err := ToSDKCoins(coins)
+	require.NoError(t, err)
+	assert.Equal(t, orig, recover)
+}
+
+func TestToSDKCoinsSorts(t *testing.T) {
+	coins, err := ToSDKCoins([]Coin{NewCoin(5, "uscrt"), NewCoin(7, "uatom")})
+	require.NoError(t, err)
+	assert.Equal(t, sdk.NewCoins(sdk.NewInt64Coin("uatom", 7), sdk.NewInt64Coin("uscrt", 5)), coins)
+
+	coins, err = ToSDKCoins(nil)
+	require.NoError(t, err)
+	assert.Empty(t, coins)
+}
+
+func TestToSDKCoinsRejectsDecimal(t *testing.T) {
+	_, err := ToSDKCoins([]Coin{{Denom: "uatom", Amount: "12.3456"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an integer")
+}
+
+func TestToSDKCoinsRejectsInvalid(t *testing.T) {
+	// duplicate denoms
+	_, err := ToSDKCoins([]Coin{NewCoin(1, "uatom"), NewCoin(2, "uatom")})
+	require.Error(t, err)
+
+	// negative amount
+	_, err = ToSDKCoins([]Coin{{Denom: "uatom", Amount: "-5"}})
+	require.Error(t, err)
+}
+
+func TestEnvFromContext(t *testing.T) {
+	blockTime := time.Unix(1600000000, 0)
+	ctx := sdk.Context{}.WithBlockHeight(1234).WithBlockTime(blockTime).WithChainID("secret-2")
+	contract := sdk.AccAddress([]byte("contract-address-123"))
+
+	env := EnvFromContext(ctx, contract, sdk.NewCoins(sdk.NewInt64Coin("uscrt", 500)))
+	assert.Equal(t, Env{
+		Block: BlockInfo{
+			Height:  1234,
+			Time:    1600000000,
+			ChainID: "secret-2",
+		},
+		Message: MessageInfo{
+			SentFunds: Coins{{Denom: "uscrt", Amount: "500"}},
+		},
+		Contract: ContractInfo{
+			Address: HumanAddress(contract.String()),
+		},
+	}, env)
+
+	// no funds still gives an empty (not nil) list
+	env = EnvFromContext(ctx, contract, nil)
+	assert.Equal(t, Coins{}, env.Message.SentFunds)
+
+	// the proposer is passed on when the block has one
+	header := ctx.BlockHeader()
+	header.ProposerAddress = []byte("proposer-address-123")
+	env = EnvFromContext(ctx.WithBlockHeader(header), contract, nil)
+	assert.Equal(t, CanonicalAddress("proposer-address-123"), env.Block.Proposer)
+
+	assert.Panics(t, func() {
+		EnvFromContext(ctx.WithBlockHeight(-1), contract, nil)
+	})
+}
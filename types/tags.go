@@ -0,0 +1,76 @@
+package types
+
+// The JSON tags of every message and query variant, so routing code can refer to them symbolically.
+// Each block lists the variants of one enum type, in field order.
+
+// CosmosMsg variants
+const (
+	MsgBank    = "bank"
+	MsgCustom  = "custom"
+	MsgStaking = "staking"
+	MsgWasm    = "wasm"
+	MsgGov     = "gov"
+)
+
+// BankMsg variants
+const (
+	BankMsgSend = "send"
+)
+
+// StakingMsg variants
+const (
+	StakingMsgDelegate   = "delegate"
+	StakingMsgUndelegate = "undelegate"
+	StakingMsgRedelegate = "redelegate"
+	StakingMsgWithdraw   = "withdraw"
+)
+
+// GovMsg variants
+const (
+	GovMsgVote         = "vote"
+	GovMsgVoteWeighted = "vote_weighted"
+)
+
+// WasmMsg variants
+const (
+	WasmMsgExecute     = "execute"
+	WasmMsgInstantiate = "instantiate"
+	WasmMsgUpdateAdmin = "update_admin"
+	WasmMsgClearAdmin  = "clear_admin"
+)
+
+// QueryRequest variants
+const (
+	QueryBank         = "bank"
+	QueryCustom       = "custom"
+	QueryStaking      = "staking"
+	QueryDistribution = "distribution"
+	QueryWasm         = "wasm"
+)
+
+// BankQuery variants
+const (
+	BankQueryBalance     = "balance"
+	BankQueryAllBalances = "all_balances"
+	BankQuerySupply      = "supply"
+)
+
+// StakingQuery variants
+const (
+	StakingQueryValidators     = "validators"
+	StakingQueryAllDelegations = "all_delegations"
+	StakingQueryDelegation     = "delegation"
+	StakingQueryBondedDenom    = "bonded_denom"
+)
+
+// DistributionQuery variants
+const (
+	DistributionQueryDelegatorWithdrawAddress = "delegator_withdraw_address"
+	DistributionQueryDelegationRewards        = "delegation_rewards"
+)
+
+// WasmQuery variants
+const (
+	WasmQuerySmart = "smart"
+	WasmQueryRaw   = "raw"
+)
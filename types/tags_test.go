@@ -0,0 +1,32 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagsMatchStructs(t *testing.T) {
+	cases := map[reflect.Type][]string{
+		reflect.TypeOf(CosmosMsg{}):         {MsgBank, MsgCustom, MsgStaking, MsgWasm, MsgGov},
+		reflect.TypeOf(BankMsg{}):           {BankMsgSend},
+		reflect.TypeOf(StakingMsg{}):        {StakingMsgDelegate, StakingMsgUndelegate, StakingMsgRedelegate, StakingMsgWithdraw},
+		reflect.TypeOf(GovMsg{}):            {GovMsgVote, GovMsgVoteWeighted},
+		reflect.TypeOf(WasmMsg{}):           {WasmMsgExecute, WasmMsgInstantiate, WasmMsgUpdateAdmin, WasmMsgClearAdmin},
+		reflect.TypeOf(QueryRequest{}):      {QueryBank, QueryCustom, QueryStaking, QueryDistribution, QueryWasm},
+		reflect.TypeOf(BankQuery{}):         {BankQueryBalance, BankQueryAllBalances, BankQuerySupply},
+		reflect.TypeOf(StakingQuery{}):      {StakingQueryValidators, StakingQueryAllDelegations, StakingQueryDelegation, StakingQueryBondedDenom},
+		reflect.TypeOf(DistributionQuery{}): {DistributionQueryDelegatorWithdrawAddress, DistributionQueryDelegationRewards},
+		reflect.TypeOf(WasmQuery{}):         {WasmQuerySmart, WasmQueryRaw},
+	}
+	for typ, tags := range cases {
+		var fields []string
+		for i := 0; i < typ.NumField(); i++ {
+			tag := typ.Field(i).Tag.Get("json")
+			fields = append(fields, strings.Split(tag, ",")[0])
+		}
+		assert.Equal(t, tags, fields, typ.Name())
+	}
+}
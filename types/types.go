@@ -2,7 +2,11 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
 	"strconv"
+	"time"
 )
 
 // HumanAddress is a printable (typically bech32 encoded) address string. Just use it as a label for developers.
@@ -24,6 +28,58 @@ func NewCoin(amount uint64, denom string) Coin {
 	}
 }
 
+// The widths of the amounts a Coin may hold. By default amounts are limited to 128 bits,
+// matching cosmwasm-std's Uint128, but tokens bridged from EVM chains may need the full 256 bits.
+const (
+	Uint128Bits = 128
+	Uint256Bits = 256
+)
+
+// maxUint128 is the largest amount a Coin may hold by default
+var maxUint128 = maxUint(Uint128Bits)
+
+// maxUint returns 2^bits - 1
+func maxUint(bits uint) *big.Int {
+	return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))
+}
+
+// AmountInt parses the Amount as a (non-negative) integer.
+// On-chain amounts are integers, so a fractional value is an error, as is a value
+// that does not fit in 128 bits.
+func (c Coin) AmountInt() (*big.Int, error) {
+	return c.AmountIntBits(Uint128Bits)
+}
+
+// AmountBig256 is like AmountInt, but allows any value that fits in 256 bits
+func (c Coin) AmountBig256() (*big.Int, error) {
+	return c.AmountIntBits(Uint256Bits)
+}
+
+// AmountIntBits is like AmountInt, but allows any value that fits in bits bits (Uint128Bits or Uint256Bits)
+func (c Coin) AmountIntBits(bits uint) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(c.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount for %s: %q is not an integer", c.Denom, c.Amount)
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount for %s: %q is negative", c.Denom, c.Amount)
+	}
+	max := maxUint128
+	if bits != Uint128Bits {
+		max = maxUint(bits)
+	}
+	if amount.Cmp(max) > 0 {
+		return nil, fmt.Errorf("invalid amount for %s: %q does not fit in %d bits", c.Denom, c.Amount, bits)
+	}
+	return amount, nil
+}
+
+// ValidateAmount checks that the Amount is an integer that fits in 128 bits
+func (c Coin) ValidateAmount() error {
+	_, err := c.AmountInt()
+	return err
+}
+
 // Coins handles properly serializing empty amounts
 type Coins []Coin
 
@@ -50,6 +106,89 @@ func (c *Coins) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Normalize returns the coins sorted by denom, with the amounts of duplicate denoms added up.
+// It returns an error if any amount (or sum) is not a valid uint128.
+func (c Coins) Normalize() (Coins, error) {
+	return c.NormalizeBits(Uint128Bits)
+}
+
+// NormalizeBits is like Normalize, but allows amounts (and sums) of up to bits bits
+func (c Coins) NormalizeBits(bits uint) (Coins, error) {
+	if len(c) == 0 {
+		return c, nil
+	}
+	sums := make(map[string]*big.Int, len(c))
+	denoms := make([]string, 0, len(c))
+	for _, coin := range c {
+		amount, err := coin.AmountIntBits(bits)
+		if err != nil {
+			return nil, err
+		}
+		if sum, ok := sums[coin.Denom]; ok {
+			sum.Add(sum, amount)
+		} else {
+			sums[coin.Denom] = amount
+			denoms = append(denoms, coin.Denom)
+		}
+	}
+	sort.Strings(denoms)
+	res := make(Coins, len(denoms))
+	for i, denom := range denoms {
+		res[i] = Coin{Denom: denom, Amount: sums[denom].String()}
+		if _, err := res[i].AmountIntBits(bits); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// NonZero returns the coins without the ones with a zero amount.
+// Coins with an invalid amount are kept, so they can still be rejected.
+func (c Coins) NonZero() Coins {
+	if len(c) == 0 {
+		return c
+	}
+	res := make(Coins, 0, len(c))
+	for _, coin := range c {
+		if amount, err := coin.AmountBig256(); err != nil || amount.Sign() != 0 {
+			res = append(res, coin)
+		}
+	}
+	return res
+}
+
+// DecCoin is a string representation of the sdk.DecCoin type
+type DecCoin struct {
+	Denom  string `json:"denom"`  // type, eg. "ATOM"
+	Amount string `json:"amount"` // string encoding of decimal value, eg. "12.3456"
+}
+
+// DecCoins handles properly serializing empty amounts
+type DecCoins []DecCoin
+
+// MarshalJSON ensures that we get [] for empty arrays
+func (c DecCoins) MarshalJSON() ([]byte, error) {
+	if len(c) == 0 {
+		return []byte("[]"), nil
+	}
+	var d []DecCoin = c
+	return json.Marshal(d)
+}
+
+// UnmarshalJSON ensures that we get [] for empty arrays
+func (c *DecCoins) UnmarshalJSON(data []byte) error {
+	// make sure we deserialize [] back to null
+	if string(data) == "[]" || string(data) == "null" {
+		return nil
+	}
+	var d []DecCoin
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	*c = d
+	return nil
+}
+
 type OutOfGasError struct{}
 
 var _ error = OutOfGasError{}
@@ -57,3 +196,85 @@ var _ error = OutOfGasError{}
 func (o OutOfGasError) Error() string {
 	return "Out of gas"
 }
+
+// MaxCallDepthError is returned when nested contract calls exceed the configured maximum depth
+type MaxCallDepthError struct {
+	Max uint32
+}
+
+var _ error = MaxCallDepthError{}
+
+func (e MaxCallDepthError) Error() string {
+	return fmt.Sprintf("max call depth of %d exceeded", e.Max)
+}
+
+// ContractPausedError is returned when executing code that was paused by the chain
+type ContractPausedError struct {
+	CodeID []byte
+}
+
+var _ error = ContractPausedError{}
+
+func (e ContractPausedError) Error() string {
+	return fmt.Sprintf("contract paused: code %X", e.CodeID)
+}
+
+// ContractVersionNotSupported is returned when a contract does not report its version
+type ContractVersionNotSupported struct {
+	Reason string
+}
+
+var _ error = ContractVersionNotSupported{}
+
+func (e ContractVersionNotSupported) Error() string {
+	return fmt.Sprintf("contract does not report its version: %s", e.Reason)
+}
+
+// ReadOnlyStoreError is returned when a contract tries to modify its storage from the query entry point
+type ReadOnlyStoreError struct {
+	// Op is the attempted operation, e.g. "set" or "delete"
+	Op string
+}
+
+var _ error = ReadOnlyStoreError{}
+
+func (e ReadOnlyStoreError) Error() string {
+	return fmt.Sprintf("cannot %s in a query: storage is read-only", e.Op)
+}
+
+// CompileTimeoutError is returned when compiling code took longer than the configured limit
+type CompileTimeoutError struct {
+	Timeout time.Duration
+}
+
+var _ error = CompileTimeoutError{}
+
+func (e CompileTimeoutError) Error() string {
+	return fmt.Sprintf("compiling code took longer than %s", e.Timeout)
+}
+
+// CancelledError is returned when the context of a vm call was done before the call finished
+type CancelledError struct {
+	// Err is the error of the context, i.e. context.Canceled or context.DeadlineExceeded
+	Err error
+}
+
+var _ error = CancelledError{}
+
+func (e CancelledError) Error() string {
+	return fmt.Sprintf("contract call cancelled: %v", e.Err)
+}
+
+func (e CancelledError) Unwrap() error {
+	return e.Err
+}
+
+// RotatedContractKeyError is returned when calling a contract with a key that was replaced
+// by Wasmer.RotateContractKey. The keys are secret, so they are not part of the error.
+type RotatedContractKeyError struct{}
+
+var _ error = RotatedContractKeyError{}
+
+func (e RotatedContractKeyError) Error() string {
+	return "contract key was rotated, calls must use the new key"
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoinAmountInt(t *testing.T) {
+	amount, err := NewCoin(12345, "uscrt").AmountInt()
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(12345), amount)
+
+	amount, err = Coin{Denom: "uscrt", Amount: "0"}.AmountInt()
+	require.NoError(t, err)
+	assert.Equal(t, 0, amount.Sign())
+
+	// fractional and garbage amounts are rejected
+	_, err = Coin{Denom: "uscrt", Amount: "12.3456"}.AmountInt()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an integer")
+	_, err = Coin{Denom: "uscrt", Amount: ""}.AmountInt()
+	require.Error(t, err)
+	_, err = Coin{Denom: "uscrt", Amount: "-1"}.AmountInt()
+	require.Error(t, err)
+}
+
+func TestCoinAmountIntUint128Boundary(t *testing.T) {
+	// 2^128 - 1 is the largest valid amount
+	max := "340282366920938463463374607431768211455"
+	amount, err := Coin{Denom: "uscrt", Amount: max}.AmountInt()
+	require.NoError(t, err)
+	assert.Equal(t, max, amount.String())
+	assert.Equal(t, 128, amount.BitLen())
+	require.NoError(t, Coin{Denom: "uscrt", Amount: max}.ValidateAmount())
+
+	// 2^128 overflows
+	tooBig := Coin{Denom: "uscrt", Amount: "340282366920938463463374607431768211456"}
+	_, err = tooBig.AmountInt()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not fit in 128 bits")
+	require.Error(t, tooBig.ValidateAmount())
+}
+
+func TestCoinAmountBig256Boundary(t *testing.T) {
+	// 2^128 is fine with 256 bits
+	amount, err := Coin{Denom: "weth", Amount: "340282366920938463463374607431768211456"}.AmountBig256()
+	require.NoError(t, err)
+	assert.Equal(t, 129, amount.BitLen())
+
+	// 2^256 - 1 is the largest valid amount
+	max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	amount, err = Coin{Denom: "weth", Amount: max}.AmountBig256()
+	require.NoError(t, err)
+	assert.Equal(t, max, amount.String())
+	assert.Equal(t, 256, amount.BitLen())
+
+	// 2^256 overflows
+	_, err = Coin{Denom: "weth", Amount: "115792089237316195423570985008687907853269984665640564039457584007913129639936"}.AmountBig256()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not fit in 256 bits")
+
+	// the other checks still apply
+	_, err = Coin{Denom: "weth", Amount: "-1"}.AmountBig256()
+	require.Error(t, err)
+
+	// sums may use the whole range too
+	half := "57896044618658097711785492504343953926634992332820282019728792003956564819968"
+	normalized, err := Coins{{Denom: "weth", Amount: half}, {Denom: "weth", Amount: "1"}}.NormalizeBits(Uint256Bits)
+	require.NoError(t, err)
+	assert.Equal(t, Coins{{Denom: "weth", Amount: "57896044618658097711785492504343953926634992332820282019728792003956564819969"}}, normalized)
+	_, err = Coins{{Denom: "weth", Amount: half}, {Denom: "weth", Amount: half}}.NormalizeBits(Uint256Bits)
+	require.Error(t, err)
+	_, err = Coins{{Denom: "weth", Amount: half}}.Normalize()
+	require.Error(t, err)
+}
+
+func TestCoinsNormalize(t *testing.T) {
+	// unsorted, with duplicates
+	coins := Coins{
+		{Denom: "uscrt", Amount: "100"},
+		{Denom: "ufoo", Amount: "7"},
+		{Denom: "uscrt", Amount: "23"},
+		{Denom: "abc", Amount: "0"},
+	}
+	normalized, err := coins.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, Coins{
+		{Denom: "abc", Amount: "0"},
+		{Denom: "ufoo", Amount: "7"},
+		{Denom: "uscrt", Amount: "123"},
+	}, normalized)
+	// the input is untouched
+	assert.Equal(t, "100", coins[0].Amount)
+
+	// normalized input stays the same
+	again, err := normalized.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, normalized, again)
+
+	empty, err := Coins(nil).Normalize()
+	require.NoError(t, err)
+	assert.Nil(t, empty)
+
+	// invalid amounts, or sums overflowing uint128, are rejected
+	_, err = Coins{{Denom: "uscrt", Amount: "1.5"}}.Normalize()
+	require.Error(t, err)
+	max := "340282366920938463463374607431768211455"
+	_, err = Coins{{Denom: "uscrt", Amount: max}, {Denom: "uscrt", Amount: "1"}}.Normalize()
+	require.Error(t, err)
+}
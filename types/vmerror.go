@@ -0,0 +1,102 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// raw pointers, e.g. "0x7ffd5c3e2a10"
+	pointerRegexp = regexp.MustCompile(`0x[0-9a-fA-F]{6,16}\b`)
+	// io errors, which carry an os specific description before the (portable) errno, e.g.
+	// "No such file or directory (os error 2)" vs "The system cannot find the file specified. (os error 2)"
+	osErrorRegexp = regexp.MustCompile(`[^:(]*\(os error (\d+)\)`)
+	// windows paths, e.g. "C:\data\wasm\..."
+	windowsPathRegexp = regexp.MustCompile(`\b[A-Za-z]:\\[^\s:'"]*`)
+	// the traps the VM reports for a wasm stack exhaustion, depending on the runtime and where it was
+	// detected. Anchored to the whole message (without backtrace), so a contract error merely
+	// mentioning the stack is left alone.
+	stackExhaustedRegexp = regexp.MustCompile(`^(Error executing Wasm: ((Wasmer runtime error: )?RuntimeError: (call stack exhausted|stack overflow( at 0x[0-9a-fA-F]+)?)|Trap: Trap \{ kind: StackOverflow \})|thread '[^']*' has overflowed its stack\nfatal runtime error: stack overflow|Execution error: Enclave: failed to execute: Maximum call stack size exceeded)$`)
+	// failures of the node itself rather than of the contract: io, the module cache and the enclave.
+	// Anchored to the start, so the text of a contract error can never make it a host error.
+	hostErrorRegexp = regexp.MustCompile(`(?i)^(io error|cache error|error (opening|reading|writing|creating) wasm|(enclave: )?SGX_ERROR_)`)
+)
+
+// StackExhaustedError is the single error message for a contract exhausting the wasm call stack
+// (e.g. by unbounded recursion), no matter how the runtime reported it.
+const StackExhaustedError = "Error executing Wasm: RuntimeError: call stack exhausted"
+
+// NormalizeVMError maps an error message returned by the VM to a platform independent form.
+// Errors may end up in the state (e.g. in a failed tx result), so all validators must
+// produce the same string, no matter which os they run on or where the memory was allocated.
+//
+// This strips backtraces, replaces pointer addresses, os specific io error descriptions
+// and path separators, and normalizes line endings. Any kind of stack exhaustion
+// becomes StackExhaustedError.
+func NormalizeVMError(msg string) string {
+	msg = strings.ReplaceAll(msg, "\r\n", "\n")
+	if i := strings.Index(msg, "stack backtrace:"); i >= 0 {
+		msg = msg[:i]
+	}
+	if stackExhaustedRegexp.MatchString(strings.TrimSpace(msg)) {
+		return StackExhaustedError
+	}
+	msg = pointerRegexp.ReplaceAllString(msg, "0x?")
+	msg = osErrorRegexp.ReplaceAllString(msg, " os error $1")
+	msg = windowsPathRegexp.ReplaceAllStringFunc(msg, func(path string) string {
+		return strings.ReplaceAll(path[2:], `\`, "/")
+	})
+	return strings.TrimSpace(msg)
+}
+
+// VMError is an error of the VM while running a contract, such as a wasm trap.
+// Its message is normalized (see NormalizeVMError), so all nodes agree on it.
+type VMError struct {
+	Msg string
+	// Backtrace is the backtrace the VM reported along with the error, if any. It depends on the
+	// node, so it is only kept for debugging outside of consensus (see cosmwasm.CaptureBacktraces).
+	Backtrace string
+}
+
+var _ error = VMError{}
+
+func (e VMError) Error() string {
+	return e.Msg
+}
+
+// VMBacktrace returns the backtrace of a raw VM error message, or "" if it has none
+func VMBacktrace(msg string) string {
+	msg = strings.ReplaceAll(msg, "\r\n", "\n")
+	i := strings.Index(msg, "stack backtrace:")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(msg[i:])
+}
+
+// HostError is a VM error caused by the node rather than by the contract, such as a failed disk read
+// while loading the module. Unlike contract errors it is not deterministic: other validators
+// (or this one on a retry) may well succeed, so it must never be treated as a failed tx.
+type HostError struct {
+	Msg string
+}
+
+var _ error = HostError{}
+
+func (e HostError) Error() string {
+	return fmt.Sprintf("host error: %s", e.Msg)
+}
+
+// IsHostErrorMessage tells if a (normalized) VM error message describes a HostError
+func IsHostErrorMessage(msg string) bool {
+	return hostErrorRegexp.MatchString(msg)
+}
+
+// IsHostError tells if err is, or wraps, a HostError. Such errors are not deterministic and should be
+// retried or abort the node, while any other VM error is a deterministic contract failure.
+func IsHostError(err error) bool {
+	var hostErr HostError
+	return errors.As(err, &hostErr)
+}
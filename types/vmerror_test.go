@@ -0,0 +1,98 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeVMError(t *testing.T) {
+	cases := map[string]struct {
+		linux      string
+		other      string
+		normalized string
+	}{
+		"pointer": {
+			linux:      "Region pointer is null at 0x7ffd5c3e2a10",
+			other:      "Region pointer is null at 0x000001F3A2B4",
+			normalized: "Region pointer is null at 0x?",
+		},
+		"io error": {
+			linux:      "Error opening Wasm file for reading: No such file or directory (os error 2)",
+			other:      "Error opening Wasm file for reading: The system cannot find the file specified. (os error 2)",
+			normalized: "Error opening Wasm file for reading: os error 2",
+		},
+		"path": {
+			linux:      "Cache error: /data/wasm/modules/abc not found",
+			other:      `Cache error: C:\data\wasm\modules\abc not found`,
+			normalized: "Cache error: /data/wasm/modules/abc not found",
+		},
+		"backtrace": {
+			linux:      "Ran out of gas\nstack backtrace:\n   0: go_cosmwasm::handle\n",
+			other:      "Ran out of gas\r\nstack backtrace:\r\n   0: go_cosmwasm::handle\r\n",
+			normalized: "Ran out of gas",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.normalized, NormalizeVMError(tc.linux))
+			assert.Equal(t, tc.normalized, NormalizeVMError(tc.other))
+		})
+	}
+
+	// plain messages are left alone
+	assert.Equal(t, "Unauthorized", NormalizeVMError("Unauthorized"))
+}
+
+func TestNormalizeVMErrorStackExhausted(t *testing.T) {
+	// what a recursive contract produces, depending on runtime and platform
+	variants := []string{
+		"Error executing Wasm: Wasmer runtime error: RuntimeError: call stack exhausted",
+		"Error executing Wasm: Trap: Trap { kind: StackOverflow }",
+		"Error executing Wasm: RuntimeError: stack overflow at 0x7ffd5c3e2a10",
+		"thread '<unnamed>' has overflowed its stack\nfatal runtime error: stack overflow",
+		"Execution error: Enclave: failed to execute: Maximum call stack size exceeded",
+		"Error executing Wasm: RuntimeError: call stack exhausted",
+		"Error executing Wasm: RuntimeError: call stack exhausted\r\nstack backtrace:\r\n   0: go_cosmwasm::handle\r\n",
+	}
+	for _, msg := range variants {
+		assert.Equal(t, StackExhaustedError, NormalizeVMError(msg), msg)
+	}
+
+	// other runtime errors are not affected
+	assert.Equal(t, "Error executing Wasm: RuntimeError: unreachable", NormalizeVMError("Error executing Wasm: RuntimeError: unreachable"))
+	// neither are contract errors talking about the stack, nor a backtrace passing through a stack frame
+	assert.Equal(t, "Generic error: stack limit of 10 items reached", NormalizeVMError("Generic error: stack limit of 10 items reached"))
+	assert.Equal(t, "Generic error: no stack overflow here", NormalizeVMError("Generic error: no stack overflow here"))
+	assert.Equal(t, "Error executing Wasm: RuntimeError: unreachable",
+		NormalizeVMError("Error executing Wasm: RuntimeError: unreachable\nstack backtrace:\n   0: std::sys::stack_overflow::handler\n"))
+}
+
+func TestIsHostError(t *testing.T) {
+	host := []string{
+		"Error opening Wasm file for reading: os error 5",
+		"Cache error: Error reading module from disk",
+		"IO error: Input/output error",
+		"Enclave: SGX_ERROR_ENCLAVE_LOST",
+	}
+	for _, msg := range host {
+		assert.True(t, IsHostErrorMessage(msg), msg)
+	}
+	contract := []string{
+		"Unauthorized",
+		"Error executing Wasm: RuntimeError: unreachable",
+		"Error calling the VM: Ran out of gas",
+		"Generic error: Cache error: os error 5",
+	}
+	for _, msg := range contract {
+		assert.False(t, IsHostErrorMessage(msg), msg)
+	}
+
+	err := fmt.Errorf("loading code: %w", HostError{Msg: "Cache error: os error 5"})
+	assert.True(t, IsHostError(err))
+	assert.EqualError(t, err, "loading code: host error: Cache error: os error 5")
+	assert.False(t, IsHostError(GenericErr{Msg: "Cache error: os error 5"}))
+}
@@ -0,0 +1,51 @@
+package cosmwasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+// ContractVersionQuery is the reserved query a contract answers with its ContractVersionInfo
+var ContractVersionQuery = []byte(`{"__info":{}}`)
+
+// ContractVersion asks a contract for its self reported name and version, using the reserved
+// ContractVersionQuery. It returns a types.ContractVersionNotSupported error if the contract
+// does not implement it (i.e. rejects the query or answers something else).
+// Errors of the vm itself (e.g. out of gas) are returned as they are.
+//
+// The enclave only accepts query messages encrypted to the contract, so queryMsg must be
+// ContractVersionQuery encrypted the same way a client encrypts any other query to this
+// contract. The call runs like one made with QueryContext, including the call hooks.
+func (w *Wasmer) ContractVersion(
+	code CodeID,
+	queryMsg []byte,
+	store KVStore,
+	goapi GoAPI,
+	querier Querier,
+	gasMeter GasMeter,
+	gasLimit uint64,
+) (*types.ContractVersionInfo, uint64, error) {
+	resp, gasUsed, err := w.runQuery(context.Background(), code, queryMsg, store, goapi, querier, gasMeter, gasLimit)
+	if resp == nil {
+		return nil, gasUsed, err
+	}
+	info, err := parseContractVersion(resp)
+	return info, gasUsed, err
+}
+
+func parseContractVersion(resp *types.QueryResponse) (*types.ContractVersionInfo, error) {
+	if resp.Err != nil {
+		return nil, types.ContractVersionNotSupported{Reason: resp.Err.Error()}
+	}
+	var info types.ContractVersionInfo
+	if err := json.Unmarshal(resp.Ok, &info); err != nil {
+		return nil, types.ContractVersionNotSupported{Reason: fmt.Sprintf("invalid response: %s", err)}
+	}
+	if info.Contract == "" || info.Version == "" {
+		return nil, types.ContractVersionNotSupported{Reason: "response is missing contract or version"}
+	}
+	return &info, nil
+}
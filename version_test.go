@@ -0,0 +1,45 @@
+package cosmwasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enigmampc/SecretNetwork/go-cosmwasm/types"
+)
+
+func TestParseContractVersion(t *testing.T) {
+	// a contract implementing the query
+	info, err := parseContractVersion(&types.QueryResponse{Ok: []byte(`{"contract":"crates.io:snip20","version":"1.0.2"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, &types.ContractVersionInfo{Contract: "crates.io:snip20", Version: "1.0.2"}, info)
+
+	// one that does not know the query
+	_, err = parseContractVersion(&types.QueryResponse{Err: &types.StdError{ParseErr: &types.ParseErr{Target: "QueryMsg", Msg: "unknown variant `__info`"}}})
+	require.Error(t, err)
+	require.IsType(t, types.ContractVersionNotSupported{}, err)
+	assert.Contains(t, err.Error(), "unknown variant")
+
+	// or answers something else
+	for _, ok := range []string{`{"count":17}`, `"1.0.2"`, `{"contract":"foo"}`} {
+		_, err = parseContractVersion(&types.QueryResponse{Ok: []byte(ok)})
+		require.Error(t, err, ok)
+		require.IsType(t, types.ContractVersionNotSupported{}, err, ok)
+	}
+}
+
+func TestContractVersionRunsHooks(t *testing.T) {
+	var entryPoints []string
+	paused := types.ContractPausedError{CodeID: codeA}
+	w := Wasmer{}
+	PreCall(func(code CodeID, entryPoint string, env *types.Env) error {
+		entryPoints = append(entryPoints, entryPoint)
+		return paused
+	})(&w)
+
+	// like any query, it is subject to the hooks
+	_, _, err := w.ContractVersion(codeA, []byte("encrypted query"), nil, GoAPI{}, nil, nil, 1000)
+	assert.Equal(t, paused, err)
+	assert.Equal(t, []string{"query"}, entryPoints)
+}
@@ -0,0 +1,298 @@
+package cosmwasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// This is a minimal reader for the wasm binary format, just enough to statically
+// inspect a module on the Go side (imports, exports, function count) without
+// calling into the vm. Full validation is still done by the vm when compiling.
+
+// ErrInvalidWasm is returned (wrapped, with more details) for code that is obviously not a wasm module
+var ErrInvalidWasm = errors.New("invalid wasm")
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d}
+var wasmVersion = []byte{0x01, 0x00, 0x00, 0x00}
+
+// wasm section ids
+const (
+	sectionImport   = 2
+	sectionFunction = 3
+	sectionExport   = 7
+)
+
+// wasm external kinds
+const (
+	externFunc   = 0
+	externTable  = 1
+	externMemory = 2
+	externGlobal = 3
+)
+
+type wasmImport struct {
+	Module string
+	Name   string
+	Kind   byte
+}
+
+type wasmExport struct {
+	Name string
+	Kind byte
+}
+
+type wasmModule struct {
+	Imports []wasmImport
+	Exports []wasmExport
+	// number of functions defined in the module itself (excluding imports)
+	Functions uint32
+}
+
+// hasExport returns true if the module exports a function with this name
+func (m *wasmModule) hasExport(name string) bool {
+	for _, e := range m.Exports {
+		if e.Kind == externFunc && e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredFeatures returns the features the module needs, declared by exporting "requires_<feature>"
+func (m *wasmModule) requiredFeatures() []string {
+	var features []string
+	for _, e := range m.Exports {
+		if e.Kind == externFunc && strings.HasPrefix(e.Name, "requires_") {
+			features = append(features, strings.TrimPrefix(e.Name, "requires_"))
+		}
+	}
+	return features
+}
+
+type wasmReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *wasmReader) done() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *wasmReader) readByte() (byte, error) {
+	if r.done() {
+		return 0, fmt.Errorf("unexpected end of module at byte %d", r.pos)
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *wasmReader) readBytes(n uint32) ([]byte, error) {
+	if uint64(r.pos)+uint64(n) > uint64(len(r.data)) {
+		return nil, fmt.Errorf("unexpected end of module at byte %d", r.pos)
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// u32 reads an unsigned LEB128 encoded integer
+func (r *wasmReader) u32() (uint32, error) {
+	var res uint32
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := r.readByte()
+		if err != nil {
+			return 0, err
+		}
+		res |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return res, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid integer encoding at byte %d", r.pos)
+}
+
+// count reads the number of entries of a vector. Every entry takes at least one byte,
+// so a count beyond the bytes left is rejected before anything is allocated for it.
+func (r *wasmReader) count() (uint32, error) {
+	n, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n) > uint64(len(r.data)-r.pos) {
+		return 0, fmt.Errorf("count of %d entries exceeds the section at byte %d", n, r.pos)
+	}
+	return n, nil
+}
+
+func (r *wasmReader) name() (string, error) {
+	n, err := r.u32()
+	if err != nil {
+		return "", err
+	}
+	b, err := r.readBytes(n)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (r *wasmReader) limits() error {
+	flags, err := r.readByte()
+	if err != nil {
+		return err
+	}
+	if _, err := r.u32(); err != nil {
+		return err
+	}
+	if flags&1 == 1 {
+		_, err = r.u32()
+	}
+	return err
+}
+
+// checkWasmHeader ensures the code starts with the wasm magic bytes and version
+func checkWasmHeader(code []byte) error {
+	if len(code) == 0 {
+		return fmt.Errorf("%w: code is empty", ErrInvalidWasm)
+	}
+	if len(code) < 8 || !bytes.Equal(code[0:4], wasmMagic) {
+		return fmt.Errorf("%w: code does not start with the wasm magic bytes", ErrInvalidWasm)
+	}
+	if !bytes.Equal(code[4:8], wasmVersion) {
+		return fmt.Errorf("%w: unsupported wasm version %X", ErrInvalidWasm, code[4:8])
+	}
+	return nil
+}
+
+// parseWasm reads the imports, exports and function count of a wasm module
+func parseWasm(code []byte) (*wasmModule, error) {
+	if err := checkWasmHeader(code); err != nil {
+		return nil, err
+	}
+	module := wasmModule{}
+	r := wasmReader{data: code, pos: 8}
+	for !r.done() {
+		id, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		payload, err := r.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		section := wasmReader{data: payload}
+		switch id {
+		case sectionImport:
+			module.Imports, err = readImports(&section)
+		case sectionFunction:
+			module.Functions, err = section.u32()
+		case sectionExport:
+			module.Exports, err = readExports(&section)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %s", id, err)
+		}
+	}
+	return &module, nil
+}
+
+func readImports(r *wasmReader) ([]wasmImport, error) {
+	count, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	imports := make([]wasmImport, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var imp wasmImport
+		if imp.Module, err = r.name(); err != nil {
+			return nil, err
+		}
+		if imp.Name, err = r.name(); err != nil {
+			return nil, err
+		}
+		if imp.Kind, err = r.readByte(); err != nil {
+			return nil, err
+		}
+		switch imp.Kind {
+		case externFunc:
+			_, err = r.u32()
+		case externTable:
+			if _, err = r.readByte(); err == nil {
+				err = r.limits()
+			}
+		case externMemory:
+			err = r.limits()
+		case externGlobal:
+			_, err = r.readBytes(2)
+		default:
+			err = fmt.Errorf("unknown import kind %d", imp.Kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, imp)
+	}
+	return imports, nil
+}
+
+func readExports(r *wasmReader) ([]wasmExport, error) {
+	count, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	exports := make([]wasmExport, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var exp wasmExport
+		if exp.Name, err = r.name(); err != nil {
+			return nil, err
+		}
+		if exp.Kind, err = r.readByte(); err != nil {
+			return nil, err
+		}
+		if _, err = r.u32(); err != nil {
+			return nil, err
+		}
+		exports = append(exports, exp)
+	}
+	return exports, nil
+}
+
+// LargeWasmSize is the code size (in bytes) above which StoreCode warns about an unusually large module
+const LargeWasmSize = 800 * 1024
+
+// deprecatedImports maps imports of older cosmwasm versions to their current replacement
+var deprecatedImports = map[string]string{
+	"c_read":              "db_read",
+	"c_write":             "db_write",
+	"c_canonical_address": "canonicalize_address",
+	"c_human_address":     "humanize_address",
+}
+
+// codeWarnings statically inspects a module, returning human readable warnings
+// for code that is valid, but may be problematic
+func codeWarnings(code []byte) ([]string, error) {
+	module, err := parseWasm(code)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []string
+	for _, imp := range module.Imports {
+		if imp.Kind != externFunc || imp.Module != "env" {
+			continue
+		}
+		if replacement, ok := deprecatedImports[imp.Name]; ok {
+			warnings = append(warnings, fmt.Sprintf("uses deprecated import %s.%s, use %s instead", imp.Module, imp.Name, replacement))
+		}
+	}
+	if len(code) > LargeWasmSize {
+		warnings = append(warnings, fmt.Sprintf("module is unusually large: %d bytes", len(code)))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,95 @@
+package cosmwasm
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	code, err := ioutil.ReadFile("./api/testdata/" + name)
+	require.NoError(t, err)
+	return code
+}
+
+func TestParseWasm(t *testing.T) {
+	module, err := parseWasm(readTestdata(t, "hackatom.wasm"))
+	require.NoError(t, err)
+	assert.Contains(t, module.Imports, wasmImport{Module: "env", Name: "db_read", Kind: externFunc})
+	assert.True(t, module.hasExport("init"))
+	assert.True(t, module.hasExport("handle"))
+	assert.True(t, module.hasExport("migrate"))
+	assert.False(t, module.hasExport("memory"))
+	assert.Equal(t, uint32(290), module.Functions)
+}
+
+func TestCodeWarnings(t *testing.T) {
+	// a current contract has nothing to complain about
+	warnings, err := codeWarnings(readTestdata(t, "hackatom.wasm"))
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// this one was built for cosmwasm 0.6
+	warnings, err = codeWarnings(readTestdata(t, "contract.wasm"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"uses deprecated import env.c_read, use db_read instead",
+		"uses deprecated import env.c_write, use db_write instead",
+		"uses deprecated import env.c_canonical_address, use canonicalize_address instead",
+		"uses deprecated import env.c_human_address, use humanize_address instead",
+	}, warnings)
+}
+
+func TestStoreCodeRejectsInvalidWasm(t *testing.T) {
+	// this is checked before we ever touch the cache
+	w := Wasmer{}
+	cases := map[string][]byte{
+		"empty":           {},
+		"nil":             nil,
+		"truncated magic": {0x00, 0x61, 0x73},
+		"bad magic":       []byte("\x7fELF\x02\x01\x01\x00\x00\x00"),
+		"bad version":     {0x00, 0x61, 0x73, 0x6d, 0x02, 0x00, 0x00, 0x00},
+	}
+	for name, code := range cases {
+		res, err := w.StoreCode(code)
+		require.Error(t, err, name)
+		assert.True(t, errors.Is(err, ErrInvalidWasm), name)
+		assert.Nil(t, res, name)
+	}
+	_, err := w.StoreCode(nil)
+	assert.Equal(t, "invalid wasm: code is empty", err.Error())
+}
+
+func TestMinimalWasm(t *testing.T) {
+	// just the header is a valid (empty) module
+	minimal := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	require.NoError(t, checkWasmHeader(minimal))
+	module, err := parseWasm(minimal)
+	require.NoError(t, err)
+	assert.Empty(t, module.Imports)
+	assert.Empty(t, module.Exports)
+	warnings, err := codeWarnings(minimal)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestParseWasmRejectsHugeCounts(t *testing.T) {
+	// an import section claiming 2^32-1 imports in 5 bytes must not allocate for all of them
+	imports := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x02, 0x05, 0xff, 0xff, 0xff, 0xff, 0x0f}
+	_, err := parseWasm(imports)
+	assert.EqualError(t, err, "section 2: count of 4294967295 entries exceeds the section at byte 5")
+	// same for exports
+	exports := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x07, 0x05, 0xff, 0xff, 0xff, 0xff, 0x0f}
+	_, err = parseWasm(exports)
+	assert.EqualError(t, err, "section 7: count of 4294967295 entries exceeds the section at byte 5")
+
+	// and everything reachable from untrusted uploads fails cleanly
+	w := Wasmer{}
+	_, err = w.EstimateStoreGas(imports)
+	assert.Error(t, err)
+	_, err = w.StoreCode(imports)
+	assert.Error(t, err)
+}